@@ -6,29 +6,22 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
-	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 
 	"webhook-receiver/config"
 	"webhook-receiver/internal/api"
+	"webhook-receiver/internal/model/schema"
 	"webhook-receiver/internal/service"
+	"webhook-receiver/internal/service/sink"
 )
 
-// middleware to set logger at handler level
-func RequestLogger(logger *zap.SugaredLogger) gin.HandlerFunc {
-	return func(c *gin.Context) {
-		start := time.Now()
-		c.Next()
-		duration := time.Since(start)
-		logger.Infof("%s %s %d %s", c.Request.Method, c.Request.URL.Path, c.Writer.Status(), duration)
-	}
-}
-
 // ConfigureLogger sets up the structured logger
-func ConfigureLogger(c config.Config) *zap.SugaredLogger {
+func ConfigureLogger(c config.Config) *zap.Logger {
 	var level zapcore.Level
 	switch c.LogLevel {
 	case "DEBUG":
@@ -59,23 +52,59 @@ func ConfigureLogger(c config.Config) *zap.SugaredLogger {
 	}
 
 	core := zapcore.NewCore(encoder, zapcore.Lock(os.Stdout), level)
-	return zap.New(core).Sugar()
+	return zap.New(core)
 }
 
 func main() {
 
-	config := config.New()
-	logger := ConfigureLogger(config)
-	logger.Infof("Starting server with batch size: %d, interval: %d seconds, post endpoint: %s", config.BatchSize, config.BatchInterval, config.ExternalPostEndpoint)
+	cfg := config.New()
+	logger := ConfigureLogger(cfg)
+	logger.Info("starting server",
+		zap.Int("batch_size", cfg.BatchSize),
+		zap.Int("batch_interval_seconds", cfg.BatchInterval),
+		zap.Strings("sinks", cfg.Sinks),
+	)
+
+	sinks, err := sink.BuildFromNames(cfg.Sinks, config.GetEnv)
+	if err != nil {
+		log.Fatalf("Failed to build sinks: %v", err)
+	}
+
+	payloadSchema, err := schema.LoadPayloadSchema()
+	if err != nil {
+		log.Fatalf("Failed to load payload schema: %v", err)
+	}
 
-	batcher := service.NewBatcher(config.BatchSize, config.BatchInterval, config.ExternalPostEndpoint, logger)
+	registry := prometheus.NewRegistry()
+
+	batcher := service.NewBatcher(cfg.BatchSize, cfg.BatchInterval, logger, service.WALConfig{
+		Dir:            cfg.WALDir,
+		MaxBytes:       cfg.WALMaxBytes,
+		SegmentBytes:   cfg.WALSegmentBytes,
+		ReplayInterval: cfg.WALReplayInterval,
+	}, sinks, service.RetryPolicy{
+		Base:       cfg.RetryBase,
+		Max:        cfg.RetryMax,
+		Multiplier: cfg.RetryMultiplier,
+		MaxElapsed: cfg.RetryMaxElapsed,
+		Jitter:     cfg.RetryJitter,
+	}, service.BreakerConfig{
+		FailureThreshold: cfg.BreakerFailureThreshold,
+		Cooldown:         cfg.BreakerCooldown,
+	}, service.DLQConfig{
+		Dir:      cfg.DLQDir,
+		Endpoint: cfg.DLQEndpoint,
+	}, registry)
 	go batcher.Run()
 
 	router := gin.New()
-	router.Use(gin.Recovery(), RequestLogger(logger))
+	router.Use(gin.Recovery(), api.RequestLogger(logger))
 
-	router.GET("/healthz", api.HealthCheck)
-	router.POST("/log", api.HandleLog(batcher))
+	router.GET("/livez", api.LivezCheck)
+	router.GET("/readyz", api.ReadyzCheck(batcher))
+	router.GET("/metrics", gin.WrapH(promhttp.HandlerFor(registry, promhttp.HandlerOpts{})))
+	router.POST("/log", api.HandleLog(batcher, payloadSchema))
+	router.POST("/dlq/replay", api.DLQReplay(batcher))
 
 	srv := &http.Server{
 		Addr:    ":8080",
@@ -87,10 +116,10 @@ func main() {
 
 	go func() {
 		<-quit
-		logger.Infof("Shutdown signal received")
+		logger.Info("shutdown signal received")
 		batcher.Stop()
 		if err := srv.Close(); err != nil {
-			logger.Errorf("Server shutdown error: %v", err)
+			logger.Error("server shutdown error", zap.Error(err))
 		}
 	}()
 
@@ -98,5 +127,5 @@ func main() {
 		log.Fatalf("Server error: %v", err)
 	}
 
-	logger.Info("Server stopped gracefully")
+	logger.Info("server stopped gracefully")
 }
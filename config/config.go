@@ -3,14 +3,40 @@ package config
 import (
 	"os"
 	"strconv"
+	"strings"
+	"time"
 )
 
 type Config struct {
-	BatchSize            int
-	BatchInterval        int
-	ExternalPostEndpoint string
-	LogLevel             string
-	LogFormat            string
+	BatchSize     int
+	BatchInterval int
+	LogLevel      string
+	LogFormat     string
+
+	WALDir            string
+	WALMaxBytes       int64
+	WALSegmentBytes   int64
+	WALReplayInterval time.Duration
+
+	// Sinks lists the enabled downstream destinations, e.g. "http,kafka,stdout".
+	// Each sink reads its own SINK_<NAME>_* configuration via GetEnv.
+	Sinks []string
+
+	RetryBase       time.Duration
+	RetryMax        time.Duration
+	RetryMultiplier float64
+	RetryMaxElapsed time.Duration
+	RetryJitter     bool
+
+	BreakerFailureThreshold int
+	BreakerCooldown         time.Duration
+
+	// DLQDir is where permanently-rejected payloads are persisted as a
+	// replayable JSONL file; empty disables file-backed persistence.
+	DLQDir string
+	// DLQEndpoint, if set, additionally forwards each dead-lettered
+	// envelope to this HTTP endpoint for alerting.
+	DLQEndpoint string
 }
 
 func New() (c Config) {
@@ -18,7 +44,20 @@ func New() (c Config) {
 	c.LogFormat = GetEnv("LOG_FORMAT", "JSON")
 	c.BatchSize = GetEnvInt("BATCH_SIZE", 5)
 	c.BatchInterval = GetEnvInt("BATCH_INTERVAL", 15)
-	c.ExternalPostEndpoint = GetEnv("POST_ENDPOINT", "https://webhook.site/68b6a469-ef5a-4ec9-992a-b78f7c7694ee")
+	c.WALDir = GetEnv("WAL_DIR", "")
+	c.WALMaxBytes = GetEnvInt64("WAL_MAX_BYTES", 256*1024*1024)
+	c.WALSegmentBytes = GetEnvInt64("WAL_SEGMENT_BYTES", 8*1024*1024)
+	c.WALReplayInterval = time.Duration(GetEnvInt("WAL_REPLAY_INTERVAL_MS", 30000)) * time.Millisecond
+	c.Sinks = GetEnvList("SINKS", []string{"http"})
+	c.RetryBase = time.Duration(GetEnvInt("RETRY_BASE_MS", 500)) * time.Millisecond
+	c.RetryMax = time.Duration(GetEnvInt("RETRY_MAX_MS", 30000)) * time.Millisecond
+	c.RetryMultiplier = GetEnvFloat("RETRY_MULTIPLIER", 2.0)
+	c.RetryMaxElapsed = time.Duration(GetEnvInt("RETRY_MAX_ELAPSED_MS", 120000)) * time.Millisecond
+	c.RetryJitter = GetEnvBool("RETRY_JITTER", true)
+	c.BreakerFailureThreshold = GetEnvInt("BREAKER_FAILURE_THRESHOLD", 5)
+	c.BreakerCooldown = time.Duration(GetEnvInt("BREAKER_COOLDOWN_MS", 30000)) * time.Millisecond
+	c.DLQDir = GetEnv("DLQ_DIR", "")
+	c.DLQEndpoint = GetEnv("DLQ_ENDPOINT", "")
 	return c
 }
 
@@ -39,3 +78,52 @@ func GetEnvInt(key string, defaultVal int) int {
 	}
 	return val
 }
+
+// GetEnvInt64 reads a 64-bit integer from environment variables
+func GetEnvInt64(key string, defaultVal int64) int64 {
+	valStr := GetEnv(key, strconv.FormatInt(defaultVal, 10))
+	val, err := strconv.ParseInt(valStr, 10, 64)
+	if err != nil {
+		return defaultVal
+	}
+	return val
+}
+
+// GetEnvFloat reads a floating-point number from environment variables.
+func GetEnvFloat(key string, defaultVal float64) float64 {
+	valStr := GetEnv(key, strconv.FormatFloat(defaultVal, 'f', -1, 64))
+	val, err := strconv.ParseFloat(valStr, 64)
+	if err != nil {
+		return defaultVal
+	}
+	return val
+}
+
+// GetEnvBool reads a boolean from environment variables.
+func GetEnvBool(key string, defaultVal bool) bool {
+	valStr := GetEnv(key, strconv.FormatBool(defaultVal))
+	val, err := strconv.ParseBool(valStr)
+	if err != nil {
+		return defaultVal
+	}
+	return val
+}
+
+// GetEnvList reads a comma-separated list from environment variables.
+func GetEnvList(key string, defaultVal []string) []string {
+	valStr, exists := os.LookupEnv(key)
+	if !exists {
+		return defaultVal
+	}
+
+	var items []string
+	for _, item := range strings.Split(valStr, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	if len(items) == 0 {
+		return defaultVal
+	}
+	return items
+}
@@ -1,29 +1,97 @@
 package api
 
 import (
+	"encoding/json"
 	"net/http"
 
 	"webhook-receiver/internal/model"
+	"webhook-receiver/internal/model/schema"
 	"webhook-receiver/internal/service"
 
 	"github.com/gin-gonic/gin"
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"go.uber.org/zap"
 )
 
-// HealthCheck returns the health status.
-func HealthCheck(c *gin.Context) {
+// LivezCheck reports that the process is alive and able to handle requests.
+func LivezCheck(c *gin.Context) {
 	c.String(http.StatusOK, "OK")
 }
 
-// HandleLog handles incoming log payloads.
-func HandleLog(batcher service.ServiceBatcher) gin.HandlerFunc {
+// ReadyChecker reports whether a dependency is ready to accept traffic.
+type ReadyChecker interface {
+	Ready() error
+}
+
+// ReadyzCheck reports whether the batcher is ready to accept traffic: its
+// goroutine is running, the WAL (if configured) is writable, and at least
+// one sink's circuit breaker is closed.
+func ReadyzCheck(checker ReadyChecker) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := checker.Ready(); err != nil {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+			return
+		}
+		c.String(http.StatusOK, "OK")
+	}
+}
+
+// HandleLog handles incoming log payloads, rejecting any that fail
+// payloadSchema (e.g. missing required fields, out-of-range values,
+// unparseable IPs) before they ever reach the batcher. It logs through the
+// per-request logger RequestLogger attaches to the context, so its own
+// accept/reject log lines carry this request's fields. Batch-level failures
+// are logged later by the batcher's own logger and do not carry the
+// request ID.
+func HandleLog(batcher service.ServiceBatcher, payloadSchema *jsonschema.Schema) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		logger := RequestLoggerFromContext(c)
+
+		body, err := c.GetRawData()
+		if err != nil {
+			logger.Warn("failed to read request body", zap.Error(err))
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		if err := schema.ValidatePayload(payloadSchema, body); err != nil {
+			logger.Warn("rejected payload failing schema validation", zap.Error(err))
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
 		var payload model.Payload
-		if err := c.ShouldBindJSON(&payload); err != nil {
+		if err := json.Unmarshal(body, &payload); err != nil {
+			logger.Warn("rejected malformed payload", zap.Error(err))
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
 
 		batcher.Add(payload)
+		logger.Debug("accepted payload", zap.Int("user_id", payload.UserID))
 		c.Status(http.StatusAccepted)
 	}
 }
+
+// DLQReplayer re-enqueues dead-lettered payloads into the main processing
+// pipeline.
+type DLQReplayer interface {
+	ReplayDLQ() (int, error)
+}
+
+// DLQReplay re-enqueues every dead-lettered payload into the batcher.
+func DLQReplay(replayer DLQReplayer) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		logger := RequestLoggerFromContext(c)
+
+		n, err := replayer.ReplayDLQ()
+		if err != nil {
+			logger.Error("failed to replay dead-letter queue", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		logger.Info("replayed dead-letter queue", zap.Int("count", n))
+		c.JSON(http.StatusOK, gin.H{"replayed": n})
+	}
+}
@@ -3,21 +3,44 @@ package api
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"sync"
 	"testing"
 
 	"webhook-receiver/internal/model"
+	"webhook-receiver/internal/model/schema"
 
 	"github.com/gin-gonic/gin"
+	"github.com/santhosh-tekuri/jsonschema/v5"
 )
 
-// MockBatcher implements the ServiceBatcher interface for testing
+// errUnready is returned by MockBatcher.Ready in tests simulating an unready batcher
+var errUnready = errors.New("batcher not ready")
+
+// errReplay is returned by MockBatcher.ReplayDLQ in tests simulating a replay failure
+var errReplay = errors.New("dlq replay failed")
+
+// testPayloadSchema compiles the embedded payload schema once for handler tests.
+func testPayloadSchema(t *testing.T) *jsonschema.Schema {
+	t.Helper()
+	sch, err := schema.LoadPayloadSchema()
+	if err != nil {
+		t.Fatalf("failed to load payload schema: %v", err)
+	}
+	return sch
+}
+
+// MockBatcher implements the ServiceBatcher, ReadyChecker, and DLQReplayer
+// interfaces for testing
 type MockBatcher struct {
-	mu      sync.Mutex
-	logs    []model.Payload
-	stopped bool
+	mu          sync.Mutex
+	logs        []model.Payload
+	stopped     bool
+	readyErr    error
+	replayCount int
+	replayErr   error
 }
 
 // Add mock implementation to track received payloads
@@ -48,12 +71,47 @@ func (m *MockBatcher) IsStopped() bool {
 	return m.stopped
 }
 
-// TestHealthCheck ensures the health check endpoint works correctly
-func TestHealthCheck(t *testing.T) {
+// Ready mock implementation; set readyErr to simulate an unready batcher
+func (m *MockBatcher) Ready() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.readyErr != nil {
+		return m.readyErr
+	}
+	return nil
+}
+
+// ReplayDLQ mock implementation; set replayErr to simulate a replay failure
+func (m *MockBatcher) ReplayDLQ() (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.replayErr != nil {
+		return 0, m.replayErr
+	}
+	return m.replayCount, nil
+}
+
+// TestLivezCheck ensures the liveness endpoint works correctly
+func TestLivezCheck(t *testing.T) {
+	router := gin.Default()
+	router.GET("/livez", LivezCheck)
+
+	req, _ := http.NewRequest(http.MethodGet, "/livez", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Result().StatusCode != http.StatusOK {
+		t.Errorf("expected 200 OK, got %d", w.Result().StatusCode)
+	}
+}
+
+// TestReadyzCheck_Ready ensures the readiness endpoint reports 200 when the batcher is ready
+func TestReadyzCheck_Ready(t *testing.T) {
 	router := gin.Default()
-	router.GET("/healthz", HealthCheck)
+	batcher := &MockBatcher{}
+	router.GET("/readyz", ReadyzCheck(batcher))
 
-	req, _ := http.NewRequest(http.MethodGet, "/healthz", nil)
+	req, _ := http.NewRequest(http.MethodGet, "/readyz", nil)
 	w := httptest.NewRecorder()
 	router.ServeHTTP(w, req)
 
@@ -62,11 +120,26 @@ func TestHealthCheck(t *testing.T) {
 	}
 }
 
+// TestReadyzCheck_NotReady ensures the readiness endpoint reports 503 when the batcher is not ready
+func TestReadyzCheck_NotReady(t *testing.T) {
+	router := gin.Default()
+	batcher := &MockBatcher{readyErr: errUnready}
+	router.GET("/readyz", ReadyzCheck(batcher))
+
+	req, _ := http.NewRequest(http.MethodGet, "/readyz", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Result().StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("expected 503 Service Unavailable, got %d", w.Result().StatusCode)
+	}
+}
+
 // TestHandleLog_ValidPayload ensures the log endpoint processes valid logs correctly
 func TestHandleLog_ValidPayload(t *testing.T) {
 	router := gin.Default()
 	batcher := &MockBatcher{}
-	router.POST("/log", HandleLog(batcher))
+	router.POST("/log", HandleLog(batcher, testPayloadSchema(t)))
 
 	payload := model.Payload{
 		UserID:    1,
@@ -98,7 +171,7 @@ func TestHandleLog_ValidPayload(t *testing.T) {
 func TestHandleLog_BadRequest(t *testing.T) {
 	router := gin.Default()
 	batcher := &MockBatcher{}
-	router.POST("/log", HandleLog(batcher))
+	router.POST("/log", HandleLog(batcher, testPayloadSchema(t)))
 
 	req, _ := http.NewRequest(http.MethodPost, "/log", bytes.NewBuffer([]byte(`invalid payload`)))
 	w := httptest.NewRecorder()
@@ -109,18 +182,117 @@ func TestHandleLog_BadRequest(t *testing.T) {
 	}
 }
 
-// TestHandleLog_EmptyPayload tests empty JSON payload
+// TestHandleLog_EmptyPayload tests that an empty JSON payload is now
+// rejected for missing required fields (user_id, total, title) instead of
+// silently flowing into the batch.
 func TestHandleLog_EmptyPayload(t *testing.T) {
 	router := gin.Default()
 	batcher := &MockBatcher{}
-	router.POST("/log", HandleLog(batcher))
+	router.POST("/log", HandleLog(batcher, testPayloadSchema(t)))
 
 	req, _ := http.NewRequest(http.MethodPost, "/log", bytes.NewBuffer([]byte(`{}`)))
 	w := httptest.NewRecorder()
 	router.ServeHTTP(w, req)
 
+	if w.Result().StatusCode != http.StatusBadRequest {
+		t.Errorf("expected 400 Bad Request for empty payload, got %d", w.Result().StatusCode)
+	}
+
+	if logs := batcher.GetLogs(); len(logs) != 0 {
+		t.Errorf("expected no logs to reach the batcher, got %d", len(logs))
+	}
+}
+
+// TestHandleLog_InvalidUserID tests that a non-positive user_id is rejected
+// by schema validation before reaching the batcher.
+func TestHandleLog_InvalidUserID(t *testing.T) {
+	router := gin.Default()
+	batcher := &MockBatcher{}
+	router.POST("/log", HandleLog(batcher, testPayloadSchema(t)))
+
+	payload := model.Payload{UserID: 0, Total: 1.0, Title: "Invalid User"}
+	body, _ := json.Marshal(payload)
+	req, _ := http.NewRequest(http.MethodPost, "/log", bytes.NewBuffer(body))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Result().StatusCode != http.StatusBadRequest {
+		t.Errorf("expected 400 Bad Request for non-positive user_id, got %d", w.Result().StatusCode)
+	}
+}
+
+// TestHandleLog_InvalidLoginIP tests that an unparseable IP address nested
+// under meta.logins is rejected by schema validation.
+func TestHandleLog_InvalidLoginIP(t *testing.T) {
+	router := gin.Default()
+	batcher := &MockBatcher{}
+	router.POST("/log", HandleLog(batcher, testPayloadSchema(t)))
+
+	body := []byte(`{"user_id":1,"total":1.0,"title":"Bad IP","meta":{"logins":[{"ip":"not-an-ip"}]}}`)
+	req, _ := http.NewRequest(http.MethodPost, "/log", bytes.NewBuffer(body))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Result().StatusCode != http.StatusBadRequest {
+		t.Errorf("expected 400 Bad Request for unparseable login IP, got %d", w.Result().StatusCode)
+	}
+}
+
+// TestHandleLog_IPv6LoginIP tests that an IPv6 login IP, not just IPv4, is
+// accepted by schema validation.
+func TestHandleLog_IPv6LoginIP(t *testing.T) {
+	router := gin.Default()
+	batcher := &MockBatcher{}
+	router.POST("/log", HandleLog(batcher, testPayloadSchema(t)))
+
+	body := []byte(`{"user_id":1,"total":1.0,"title":"IPv6 Login","meta":{"logins":[{"ip":"2001:db8::1"}]}}`)
+	req, _ := http.NewRequest(http.MethodPost, "/log", bytes.NewBuffer(body))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
 	if w.Result().StatusCode != http.StatusAccepted {
-		t.Errorf("expected 202 Accepted for empty payload, got %d", w.Result().StatusCode)
+		t.Errorf("expected 202 Accepted for a valid IPv6 login IP, got %d", w.Result().StatusCode)
+	}
+}
+
+// TestDLQReplay_Success ensures the replay endpoint reports the number of
+// dead-lettered payloads re-enqueued.
+func TestDLQReplay_Success(t *testing.T) {
+	router := gin.Default()
+	batcher := &MockBatcher{replayCount: 3}
+	router.POST("/dlq/replay", DLQReplay(batcher))
+
+	req, _ := http.NewRequest(http.MethodPost, "/dlq/replay", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Result().StatusCode != http.StatusOK {
+		t.Errorf("expected 200 OK, got %d", w.Result().StatusCode)
+	}
+
+	var resp struct {
+		Replayed int `json:"replayed"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if resp.Replayed != 3 {
+		t.Errorf("expected replayed count 3, got %d", resp.Replayed)
+	}
+}
+
+// TestDLQReplay_Error ensures a replay failure surfaces as a 500.
+func TestDLQReplay_Error(t *testing.T) {
+	router := gin.Default()
+	batcher := &MockBatcher{replayErr: errReplay}
+	router.POST("/dlq/replay", DLQReplay(batcher))
+
+	req, _ := http.NewRequest(http.MethodPost, "/dlq/replay", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Result().StatusCode != http.StatusInternalServerError {
+		t.Errorf("expected 500 Internal Server Error, got %d", w.Result().StatusCode)
 	}
 }
 
@@ -128,7 +300,7 @@ func TestHandleLog_EmptyPayload(t *testing.T) {
 func TestHandleLog_Concurrency(t *testing.T) {
 	router := gin.Default()
 	batcher := &MockBatcher{}
-	router.POST("/log", HandleLog(batcher))
+	router.POST("/log", HandleLog(batcher, testPayloadSchema(t)))
 
 	var wg sync.WaitGroup
 	payload := model.Payload{
@@ -164,7 +336,7 @@ func TestHandleLog_Concurrency(t *testing.T) {
 func TestHandleLog_LargePayload(t *testing.T) {
 	router := gin.Default()
 	batcher := &MockBatcher{}
-	router.POST("/log", HandleLog(batcher))
+	router.POST("/log", HandleLog(batcher, testPayloadSchema(t)))
 
 	payload := model.Payload{
 		UserID:    999,
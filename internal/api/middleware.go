@@ -4,14 +4,54 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
 
-func RequestLogger(logger *zap.SugaredLogger) gin.HandlerFunc {
+// loggerContextKey is the Gin context key RequestLogger stores the
+// per-request logger under.
+const loggerContextKey = "requestLogger"
+
+// RequestLogger attaches a per-request child logger (tagged with
+// request_id, remote_ip, method, and path) to the Gin context so
+// downstream handlers can log with request-level correlation, and logs
+// each request's outcome once it completes. A request without an
+// X-Request-ID header gets one generated; the header is echoed back on
+// the response either way.
+func RequestLogger(logger *zap.Logger) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		requestID := c.GetHeader("X-Request-ID")
+		if requestID == "" {
+			requestID = uuid.New().String()
+		}
+		c.Header("X-Request-ID", requestID)
+
+		reqLogger := logger.With(
+			zap.String("request_id", requestID),
+			zap.String("remote_ip", c.ClientIP()),
+			zap.String("method", c.Request.Method),
+			zap.String("path", c.Request.URL.Path),
+		)
+		c.Set(loggerContextKey, reqLogger)
+
 		start := time.Now()
 		c.Next()
-		duration := time.Since(start)
-		logger.Infof("%s %s %d %s", c.Request.Method, c.Request.URL.Path, c.Writer.Status(), duration)
+
+		reqLogger.Info("handled request",
+			zap.Int("status", c.Writer.Status()),
+			zap.Duration("duration", time.Since(start)),
+		)
+	}
+}
+
+// RequestLoggerFromContext returns the per-request logger attached by
+// RequestLogger, falling back to a no-op logger if none is present (e.g.
+// in handler tests that call a handler directly without the middleware).
+func RequestLoggerFromContext(c *gin.Context) *zap.Logger {
+	if v, ok := c.Get(loggerContextKey); ok {
+		if l, ok := v.(*zap.Logger); ok {
+			return l
+		}
 	}
+	return zap.NewNop()
 }
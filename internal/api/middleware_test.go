@@ -0,0 +1,55 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// TestRequestLogger_GeneratesRequestID ensures a request without an
+// X-Request-ID header gets one generated and echoed back.
+func TestRequestLogger_GeneratesRequestID(t *testing.T) {
+	router := gin.Default()
+	router.Use(RequestLogger(zap.NewNop()))
+	router.GET("/ping", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	req, _ := http.NewRequest(http.MethodGet, "/ping", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Header().Get("X-Request-ID") == "" {
+		t.Error("expected X-Request-ID header to be set")
+	}
+}
+
+// TestRequestLogger_PreservesRequestID ensures a client-supplied
+// X-Request-ID is echoed back unchanged rather than replaced.
+func TestRequestLogger_PreservesRequestID(t *testing.T) {
+	router := gin.Default()
+	router.Use(RequestLogger(zap.NewNop()))
+	router.GET("/ping", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	req, _ := http.NewRequest(http.MethodGet, "/ping", nil)
+	req.Header.Set("X-Request-ID", "client-supplied-id")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if got := w.Header().Get("X-Request-ID"); got != "client-supplied-id" {
+		t.Errorf("expected X-Request-ID %q to be preserved, got %q", "client-supplied-id", got)
+	}
+}
+
+// TestRequestLoggerFromContext_FallsBackToNop ensures a handler called
+// without RequestLogger in the chain still gets a usable logger.
+func TestRequestLoggerFromContext_FallsBackToNop(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+
+	logger := RequestLoggerFromContext(c)
+	if logger == nil {
+		t.Fatal("expected a non-nil fallback logger")
+	}
+}
@@ -0,0 +1,50 @@
+// Package schema loads and applies the JSON Schema that incoming payloads
+// must satisfy before they're accepted into the batch pipeline.
+package schema
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"fmt"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+//go:embed payload.schema.json
+var files embed.FS
+
+const payloadSchemaID = "payload.schema.json"
+
+// LoadPayloadSchema compiles the embedded payload schema. Call once at
+// startup; the returned Schema is safe for concurrent use by every request.
+func LoadPayloadSchema() (*jsonschema.Schema, error) {
+	data, err := files.ReadFile(payloadSchemaID)
+	if err != nil {
+		return nil, fmt.Errorf("schema: read embedded schema: %w", err)
+	}
+
+	compiler := jsonschema.NewCompiler()
+	compiler.AssertFormat = true
+	if err := compiler.AddResource(payloadSchemaID, bytes.NewReader(data)); err != nil {
+		return nil, fmt.Errorf("schema: add resource: %w", err)
+	}
+
+	sch, err := compiler.Compile(payloadSchemaID)
+	if err != nil {
+		return nil, fmt.Errorf("schema: compile: %w", err)
+	}
+	return sch, nil
+}
+
+// ValidatePayload validates raw JSON payload bytes against sch.
+func ValidatePayload(sch *jsonschema.Schema, data []byte) error {
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return fmt.Errorf("schema: decode payload: %w", err)
+	}
+	if err := sch.Validate(v); err != nil {
+		return fmt.Errorf("schema: validation failed: %w", err)
+	}
+	return nil
+}
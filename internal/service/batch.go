@@ -1,26 +1,43 @@
 package service
 
 import (
-	"bytes"
-	"encoding/json"
+	"context"
 	"errors"
-	"net/http"
+	"fmt"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"webhook-receiver/internal/model"
+	"webhook-receiver/internal/service/sink"
 
+	"github.com/prometheus/client_golang/prometheus"
 	"go.uber.org/zap"
 )
 
+// errCircuitOpen is returned by sendWithRetry when a sink's circuit breaker
+// is tripped and the send is short-circuited without an attempt. sendBatch
+// handles it like any other failed send: the batch is spilled to the WAL.
+var errCircuitOpen = errors.New("circuit breaker open")
+
 // Batcher handles log batching based on size and time interval using channels.
 type Batcher struct {
 	payloadCh chan model.Payload
 	size      int
 	interval  time.Duration
-	endpoint  string
-	logger    *zap.SugaredLogger
+	sinks     []sink.Sink
+	logger    *zap.Logger
 	done      chan struct{}
 	quit      chan struct{}
+
+	wal            *wal
+	walReplayEvery time.Duration
+	retryPolicy    RetryPolicy
+	breakers       map[string]*circuitBreaker
+	dlq            *deadLetterQueue
+
+	metrics *Metrics
+	running int32
 }
 
 // ServiceBatcher defines methods to add payloads and stop the batch processor.
@@ -29,41 +46,230 @@ type ServiceBatcher interface {
 	Stop()
 }
 
-// NewBatcher initializes the batch processor.
-func NewBatcher(size, interval int, endpoint string, logger *zap.SugaredLogger) *Batcher {
+// NewBatcher initializes the batch processor. walCfg.Dir controls whether
+// payloads that can't be queued in memory are spilled to disk instead of
+// dropped; a zero-value WALConfig preserves the old drop-on-full behavior.
+// Every batch is fanned out to each sink independently, with its own
+// exponential backoff and circuit breaker. A sink's permanent rejections are
+// routed to the dead-letter queue described by dlqCfg instead of being
+// retried forever. Metrics are registered against reg.
+func NewBatcher(size, interval int, logger *zap.Logger, walCfg WALConfig, sinks []sink.Sink, retryPolicy RetryPolicy, breakerCfg BreakerConfig, dlqCfg DLQConfig, reg prometheus.Registerer) *Batcher {
+	w, err := newWAL(walCfg)
+	if err != nil {
+		logger.Error("failed to open WAL, disk spill disabled", zap.Error(err))
+	}
+
+	dlq, err := newDeadLetterQueue(dlqCfg)
+	if err != nil {
+		logger.Error("failed to open dead-letter queue, permanent rejections will only be logged", zap.Error(err))
+	}
+
+	breakers := make(map[string]*circuitBreaker, len(sinks))
+	for _, s := range sinks {
+		breakers[s.Name()] = newCircuitBreaker(breakerCfg)
+	}
+
+	walReplayEvery := walCfg.ReplayInterval
+	if walReplayEvery <= 0 {
+		walReplayEvery = defaultWALReplayInterval
+	}
+
 	return &Batcher{
-		payloadCh: make(chan model.Payload, size*2), // Buffered channel for non-blocking
-		size:      size,
-		interval:  time.Duration(interval) * time.Second,
-		endpoint:  endpoint,
-		logger:    logger,
-		done:      make(chan struct{}),
-		quit:      make(chan struct{}),
+		payloadCh:      make(chan model.Payload, size*2), // Buffered channel for non-blocking
+		size:           size,
+		interval:       time.Duration(interval) * time.Second,
+		sinks:          sinks,
+		logger:         logger,
+		done:           make(chan struct{}),
+		quit:           make(chan struct{}),
+		wal:            w,
+		walReplayEvery: walReplayEvery,
+		retryPolicy:    retryPolicy,
+		breakers:       breakers,
+		dlq:            dlq,
+		metrics:        NewMetrics(reg),
 	}
 }
 
-// Add pushes a payload to the channel.
+// Add pushes a payload to the channel, spilling to the WAL (if configured)
+// rather than dropping the payload when the channel is full.
 func (b *Batcher) Add(payload model.Payload) {
 	select {
 	case b.payloadCh <- payload:
-		b.logger.Debug("Added payload to channel")
+		if ce := b.logger.Check(zap.DebugLevel, "added payload to channel"); ce != nil {
+			ce.Write(zap.Int("user_id", payload.UserID))
+		}
+		b.metrics.PayloadsReceived.Inc()
 	default:
-		b.logger.Warn("Payload channel is full, dropping payload")
+		if b.wal == nil {
+			b.logger.Warn("payload channel is full, dropping payload")
+			b.metrics.PayloadsDropped.WithLabelValues("channel_full_no_wal").Inc()
+			return
+		}
+		if _, err := b.wal.Append(payload); err != nil {
+			b.logger.Error("payload channel is full and WAL spill failed, dropping payload", zap.Error(err))
+			b.metrics.PayloadsDropped.WithLabelValues("wal_append_failed").Inc()
+			return
+		}
+		b.logger.Warn("payload channel is full, spilled payload to WAL")
+		b.metrics.PayloadsReceived.Inc()
+		b.updateWALGauge()
+	}
+}
+
+// PendingBytes reports the number of bytes currently held in unacknowledged
+// WAL segments, for observability. Returns 0 when no WAL is configured.
+func (b *Batcher) PendingBytes() int64 {
+	if b.wal == nil {
+		return 0
+	}
+	return b.wal.PendingBytes()
+}
+
+// updateWALGauge refreshes the wal_pending_bytes gauge after a WAL mutation.
+func (b *Batcher) updateWALGauge() {
+	b.metrics.WALPendingBytes.Set(float64(b.PendingBytes()))
+}
+
+// Ready reports whether the batcher is healthy enough to accept traffic:
+// its goroutine is running, the WAL (if configured) is writable, and at
+// least one sink's circuit breaker is not Open.
+func (b *Batcher) Ready() error {
+	if atomic.LoadInt32(&b.running) == 0 {
+		return errors.New("batcher goroutine is not running")
+	}
+
+	if b.wal != nil {
+		if err := b.wal.CheckWritable(); err != nil {
+			return fmt.Errorf("wal not writable: %w", err)
+		}
+	}
+
+	if len(b.breakers) == 0 {
+		return nil
+	}
+	for _, breaker := range b.breakers {
+		if !breaker.IsOpen() {
+			return nil
+		}
+	}
+	return errors.New("all sink circuit breakers are open")
+}
+
+// replayWAL sends every unacknowledged WAL segment to every sink that
+// hasn't already ACK'd it, truncating a segment only once every configured
+// sink has taken delivery or permanently rejected it (a permanent rejection
+// is dead-lettered and recorded as an ACK, not resent). Sinks that
+// succeeded on an earlier, partially failed attempt are skipped so they don't receive the segment twice. It
+// runs once before the batcher starts consuming from the live channel, and
+// then again on every walReplayEvery tick so a segment spilled mid-run
+// doesn't sit on disk until the process restarts.
+func (b *Batcher) replayWAL() {
+	if b.wal == nil {
+		return
+	}
+
+	if err := b.wal.Rotate(); err != nil {
+		b.logger.Error("failed to rotate WAL segment before replay", zap.Error(err))
+	}
+
+	segments, err := b.wal.PendingSegments()
+	if err != nil {
+		b.logger.Error("failed to read WAL segments for replay", zap.Error(err))
+		return
+	}
+
+	for _, segment := range segments {
+		pending := b.pendingSinks(segment.ackedSinks)
+		if len(pending) == 0 {
+			if err := b.wal.Ack(segment); err != nil {
+				b.logger.Error("failed to ack fully-delivered WAL segment", zap.String("segment", segment.path), zap.Error(err))
+			}
+			b.updateWALGauge()
+			continue
+		}
+
+		b.logger.Info("replaying WAL segment",
+			zap.Int("payload_count", len(segment.payloads)),
+			zap.Int("pending_sinks", len(pending)),
+			zap.String("segment", segment.path),
+		)
+
+		acked := segment.ackedSinks
+		for name, err := range b.dispatch(segment.payloads, pending) {
+			if err != nil && !errors.Is(err, sink.ErrPermanent) {
+				b.logger.Error("failed to replay WAL segment to sink, will retry next run",
+					zap.String("segment", segment.path),
+					zap.String("sink", name),
+					zap.Error(err),
+				)
+				continue
+			}
+			if err != nil {
+				b.logger.Error("sink permanently rejected WAL segment, not retrying",
+					zap.String("segment", segment.path),
+					zap.String("sink", name),
+					zap.Error(err),
+				)
+			}
+			if err := b.wal.AckSink(segment, name); err != nil {
+				b.logger.Error("failed to record per-sink ack for WAL segment",
+					zap.String("segment", segment.path),
+					zap.String("sink", name),
+					zap.Error(err),
+				)
+				continue
+			}
+			acked[name] = true
+		}
+
+		if len(acked) < len(b.sinks) {
+			continue
+		}
+		if err := b.wal.Ack(segment); err != nil {
+			b.logger.Error("failed to ack WAL segment after replay", zap.String("segment", segment.path), zap.Error(err))
+		}
+		b.updateWALGauge()
+	}
+}
+
+// pendingSinks returns the configured sinks not yet present in acked.
+func (b *Batcher) pendingSinks(acked map[string]bool) []sink.Sink {
+	pending := make([]sink.Sink, 0, len(b.sinks))
+	for _, s := range b.sinks {
+		if !acked[s.Name()] {
+			pending = append(pending, s)
+		}
 	}
+	return pending
 }
 
 // Run starts the batch processing.
 func (b *Batcher) Run() {
+	atomic.StoreInt32(&b.running, 1)
+	defer atomic.StoreInt32(&b.running, 0)
+
+	b.replayWAL()
+
 	ticker := time.NewTicker(b.interval)
 	defer ticker.Stop()
 
+	var walReplayCh <-chan time.Time
+	if b.wal != nil {
+		walReplayTicker := time.NewTicker(b.walReplayEvery)
+		defer walReplayTicker.Stop()
+		walReplayCh = walReplayTicker.C
+	}
+
 	var batch []model.Payload
 
 	for {
 		select {
 		case payload := <-b.payloadCh:
 			batch = append(batch, payload)
-			b.logger.Debug("Received payload from channel")
+			if ce := b.logger.Check(zap.DebugLevel, "received payload from channel"); ce != nil {
+				ce.Write(zap.Int("batch_len", len(batch)))
+			}
 			if len(batch) >= b.size {
 				b.sendBatch(batch)
 				batch = nil
@@ -73,8 +279,10 @@ func (b *Batcher) Run() {
 				b.sendBatch(batch)
 				batch = nil
 			}
+		case <-walReplayCh:
+			b.replayWAL()
 		case <-b.done:
-			b.logger.Info("Received shutdown signal, flushing remaining payloads")
+			b.logger.Info("received shutdown signal, flushing remaining payloads")
 			if len(batch) > 0 {
 				b.sendBatch(batch)
 			}
@@ -84,50 +292,247 @@ func (b *Batcher) Run() {
 	}
 }
 
-// Stop batch processor gracefully.
+// Stop batch processor gracefully, closing every sink and the WAL.
 func (b *Batcher) Stop() {
 	close(b.done)
 	<-b.quit
+
+	for _, s := range b.sinks {
+		if err := s.Close(); err != nil {
+			b.logger.Error("failed to close sink", zap.String("sink", s.Name()), zap.Error(err))
+		}
+	}
+	if b.wal != nil {
+		if err := b.wal.Close(); err != nil {
+			b.logger.Error("failed to close WAL", zap.Error(err))
+		}
+	}
 }
 
-// sendBatch sends a batch of payloads to the endpoint.
+// sendBatch fans a batch out to every configured sink. If delivery ultimately
+// fails on any sink, the batch is spilled to the WAL (when configured) rather
+// than dropped, so it can be replayed once that sink recovers, whether the
+// failure tripped the circuit breaker or just ran out the retry budget. Sinks
+// that already succeeded live, or that permanently rejected the batch (it is
+// dead-lettered instead), have their ACK recorded against the spilled
+// segment immediately, so replay only resends to the sinks that actually
+// need it.
 func (b *Batcher) sendBatch(batch []model.Payload) {
 	if len(batch) == 0 {
-		b.logger.Warn("Attempted to send an empty batch")
+		b.logger.Warn("attempted to send an empty batch")
 		return
 	}
 
-	data, err := json.Marshal(batch)
-	if err != nil {
-		b.logger.Errorf("Failed to serialize batch: %v", err)
+	start := time.Now()
+	results := b.dispatch(batch, b.sinks)
+	duration := time.Since(start)
+
+	b.metrics.BatchSize.Observe(float64(len(batch)))
+	b.metrics.BatchSendDuration.Observe(duration.Seconds())
+
+	failed := 0
+	for name, err := range results {
+		if err != nil {
+			failed++
+			b.logger.Error("sink failed to send batch", zap.String("sink", name), zap.Error(err))
+		}
+	}
+
+	if failed == 0 {
+		b.metrics.BatchesSent.WithLabelValues("success").Inc()
+		b.logger.Info("successfully sent batch",
+			zap.Int("batch_len", len(batch)),
+			zap.Int("sink_count", len(results)),
+			zap.Duration("duration", duration),
+		)
 		return
 	}
 
-	start := time.Now()
-	err = b.postWithRetry(data, 3, 2*time.Second)
-	duration := time.Since(start)
+	b.metrics.BatchesSent.WithLabelValues("failure").Inc()
+	b.logger.Error("batch failed on one or more sinks",
+		zap.Int("batch_len", len(batch)),
+		zap.Int("failed_sinks", failed),
+		zap.Int("sink_count", len(results)),
+	)
+	if b.wal == nil {
+		return
+	}
 
-	if err != nil {
-		b.logger.Errorf("Failed to send batch after retries: %v", err)
-	} else {
-		b.logger.Infof("Successfully sent batch of %d records in %v", len(batch), duration)
+	// A segment otherwise stays open across many unrelated spills until it
+	// hits WAL_SEGMENT_BYTES or a replay tick rotates it. Rolling it both
+	// before and after this batch's append guarantees the segment(s) it
+	// lands on hold only this batch's payloads, so the AckSink calls below
+	// can't mark some other, independently-dispatched batch's payloads as
+	// delivered to a sink that never actually received them.
+	if err := b.wal.Rotate(); err != nil {
+		b.logger.Error("failed to rotate WAL segment before spilling failed batch", zap.Error(err))
+	}
+	defer func() {
+		if err := b.wal.Rotate(); err != nil {
+			b.logger.Error("failed to rotate WAL segment after spilling failed batch", zap.Error(err))
+		}
+	}()
+
+	touchedSegments := make(map[string]bool)
+	for i, p := range batch {
+		path, err := b.wal.Append(p)
+		if err != nil {
+			dropped := len(batch) - i
+			b.logger.Error("failed to spill failed batch to WAL, dropping undelivered remainder",
+				zap.Error(err), zap.Int("dropped_payloads", dropped))
+			b.metrics.PayloadsDropped.WithLabelValues("wal_append_failed").Add(float64(dropped))
+			b.updateWALGauge()
+			return
+		}
+		touchedSegments[path] = true
+	}
+	b.updateWALGauge()
+
+	// Sinks that already took live delivery (or permanently rejected the
+	// batch) shouldn't be resent the segment on the next replay, so record
+	// their ACK against every segment the batch landed on (ordinarily one,
+	// but a mid-batch segment roll can split it across two) rather than
+	// leaving replay to assume none of them have it yet.
+	for path := range touchedSegments {
+		segment := walSegment{path: path}
+		for name, err := range results {
+			if err != nil && !errors.Is(err, sink.ErrPermanent) {
+				continue
+			}
+			if err := b.wal.AckSink(segment, name); err != nil {
+				b.logger.Error("failed to record live-delivery ack for spilled batch",
+					zap.String("sink", name), zap.String("segment", path), zap.Error(err))
+			}
+		}
+	}
+}
+
+// dispatch copies batch to every sink in sinks concurrently, retrying each
+// sink independently so a slow or unavailable sink can't block the others,
+// and returns each sink's final error keyed by name.
+func (b *Batcher) dispatch(batch []model.Payload, sinks []sink.Sink) map[string]error {
+	results := make(map[string]error, len(sinks))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	for _, s := range sinks {
+		s := s
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := b.sendWithRetry(s, batch)
+			mu.Lock()
+			results[s.Name()] = err
+			mu.Unlock()
+		}()
 	}
+
+	wg.Wait()
+	return results
 }
 
-// postWithRetry attempts to send a batch, retrying if necessary.
-func (b *Batcher) postWithRetry(data []byte, maxRetries int, delay time.Duration) error {
-	for i := 0; i < maxRetries; i++ {
-		resp, err := http.Post(b.endpoint, "application/json", bytes.NewBuffer(data))
-		if err == nil && resp.StatusCode < 300 {
+// sendWithRetry attempts to deliver batch to a single sink, backing off
+// exponentially between attempts per retryPolicy and consulting that sink's
+// circuit breaker so a consistently failing sink stops being hammered.
+// Permanent (non-retryable) errors give up immediately.
+func (b *Batcher) sendWithRetry(s sink.Sink, batch []model.Payload) error {
+	breaker := b.breakers[s.Name()]
+	start := time.Now()
+
+	defer func() {
+		up := 1.0
+		if breaker != nil && breaker.IsOpen() {
+			up = 0.0
+		}
+		b.metrics.SinkUp.WithLabelValues(s.Name()).Set(up)
+	}()
+
+	var err error
+	for attempt := 1; ; attempt++ {
+		if breaker != nil && !breaker.Allow() {
+			return fmt.Errorf("sink %q: %w", s.Name(), errCircuitOpen)
+		}
+
+		err = s.Send(context.Background(), batch)
+		if err == nil {
+			if breaker != nil {
+				breaker.RecordSuccess()
+			}
 			return nil
 		}
 
-		if resp != nil && resp.Body != nil {
-			resp.Body.Close()
+		if breaker != nil {
+			breaker.RecordFailure()
+		}
+
+		if errors.Is(err, sink.ErrPermanent) {
+			b.deadLetter(s.Name(), batch, err, attempt)
+			return fmt.Errorf("sink %q: permanent failure, not retrying: %w", s.Name(), err)
 		}
 
-		b.logger.Warnf("Batch send attempt %d failed, retrying in %v", i+1, delay)
+		if b.retryPolicy.MaxElapsed > 0 && time.Since(start) >= b.retryPolicy.MaxElapsed {
+			return fmt.Errorf("sink %q: max elapsed retry time exceeded: %w", s.Name(), err)
+		}
+
+		delay := b.retryPolicy.backoff(attempt)
+		b.metrics.RetryAttempts.Inc()
+		b.logger.Warn("sink send attempt failed, retrying",
+			zap.String("sink", s.Name()),
+			zap.Int("attempt", attempt),
+			zap.Duration("retry_in", delay),
+			zap.Error(err),
+		)
 		time.Sleep(delay)
 	}
-	return errors.New("max retries exceeded")
+}
+
+// deadLetter routes every payload in batch to the dead-letter queue (if
+// configured) after sinkName permanently rejects them, recovering the
+// rejection's status code and response snippet from sendErr when available.
+func (b *Batcher) deadLetter(sinkName string, batch []model.Payload, sendErr error, attempts int) {
+	if b.dlq == nil {
+		return
+	}
+
+	envelope := DLQEnvelope{
+		Sink:      sinkName,
+		Timestamp: time.Now(),
+		Attempts:  attempts,
+	}
+	var httpErr *sink.HTTPError
+	if errors.As(sendErr, &httpErr) {
+		envelope.StatusCode = httpErr.StatusCode
+		envelope.ResponseBody = httpErr.Body
+	}
+
+	deadLettered := 0
+	for _, p := range batch {
+		envelope.Payload = p
+		if err := b.dlq.Add(envelope); err != nil {
+			b.logger.Error("failed to dead-letter payload", zap.String("sink", sinkName), zap.Error(err))
+			continue
+		}
+		deadLettered++
+	}
+	if deadLettered > 0 {
+		b.metrics.PayloadsDeadLettered.WithLabelValues(sinkName).Add(float64(deadLettered))
+	}
+}
+
+// ReplayDLQ drains every dead-lettered envelope and re-adds its payload to
+// the batcher's normal processing pipeline, returning the number replayed.
+// Returns 0, nil if no file-backed dead-letter queue is configured.
+func (b *Batcher) ReplayDLQ() (int, error) {
+	if b.dlq == nil {
+		return 0, nil
+	}
+
+	envelopes, err := b.dlq.Drain()
+	if err != nil {
+		return 0, fmt.Errorf("replay dead-letter queue: %w", err)
+	}
+	for _, e := range envelopes {
+		b.Add(e.Payload)
+	}
+	return len(envelopes), nil
 }
@@ -1,24 +1,44 @@
 package service
 
 import (
+	"bytes"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"sync"
 	"testing"
 	"time"
 
 	"webhook-receiver/internal/model"
+	"webhook-receiver/internal/service/sink"
 
+	"github.com/prometheus/client_golang/prometheus"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 )
 
 // Creates a test logger for capturing logs during testing
-func getTestLogger() *zap.SugaredLogger {
+func getTestLogger() *zap.Logger {
 	cfg := zap.NewDevelopmentConfig()
 	cfg.EncoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
 	logger, _ := cfg.Build()
-	return logger.Sugar()
+	return logger
+}
+
+// testRetryPolicy keeps retry tests fast while still exercising backoff.
+var testRetryPolicy = RetryPolicy{
+	Base:       50 * time.Millisecond,
+	Max:        200 * time.Millisecond,
+	Multiplier: 2,
+	MaxElapsed: 5 * time.Second,
+	Jitter:     false,
+}
+
+// testBreakerConfig uses a high failure threshold so retry tests don't trip
+// the breaker before their expected number of attempts completes.
+var testBreakerConfig = BreakerConfig{
+	FailureThreshold: 10,
+	Cooldown:         time.Second,
 }
 
 // Tests the addition of payloads and batch processing when the batch size is reached.
@@ -38,7 +58,7 @@ func TestBatcher_AddAndProcessBySize(t *testing.T) {
 	}))
 	defer testServer.Close()
 
-	batcher := NewBatcher(2, 5, testServer.URL, logger)
+	batcher := NewBatcher(2, 5, logger, WALConfig{Dir: t.TempDir()}, []sink.Sink{sink.NewHTTPSink(testServer.URL)}, testRetryPolicy, testBreakerConfig, DLQConfig{}, prometheus.NewRegistry())
 	go batcher.Run()
 
 	// Simulate adding payloads
@@ -64,7 +84,7 @@ func TestBatcher_AddAndProcessBySize(t *testing.T) {
 // Verifies that the batcher shuts down gracefully without leaving active processes.
 func TestBatcher_GracefulShutdown(t *testing.T) {
 	logger := getTestLogger()
-	batcher := NewBatcher(2, 1, "http://localhost", logger)
+	batcher := NewBatcher(2, 1, logger, WALConfig{Dir: t.TempDir()}, []sink.Sink{sink.NewHTTPSink("http://localhost")}, testRetryPolicy, testBreakerConfig, DLQConfig{}, prometheus.NewRegistry())
 	go batcher.Run()
 
 	batcher.Stop()
@@ -93,7 +113,7 @@ func TestBatcher_RetryLogic(t *testing.T) {
 	}))
 	defer testServer.Close()
 
-	batcher := NewBatcher(1, 1, testServer.URL, logger)
+	batcher := NewBatcher(1, 1, logger, WALConfig{Dir: t.TempDir()}, []sink.Sink{sink.NewHTTPSink(testServer.URL)}, testRetryPolicy, testBreakerConfig, DLQConfig{}, prometheus.NewRegistry())
 	go batcher.Run()
 
 	payload := model.Payload{
@@ -115,7 +135,7 @@ func TestBatcher_RetryLogic(t *testing.T) {
 // Tests the handling of an empty batch to ensure it is skipped gracefully.
 func TestBatcher_EmptyBatch(t *testing.T) {
 	logger := getTestLogger()
-	batcher := NewBatcher(1, 1, "http://localhost", logger)
+	batcher := NewBatcher(1, 1, logger, WALConfig{Dir: t.TempDir()}, []sink.Sink{sink.NewHTTPSink("http://localhost")}, testRetryPolicy, testBreakerConfig, DLQConfig{}, prometheus.NewRegistry())
 
 	// Intentionally sending an empty batch
 	batcher.sendBatch([]model.Payload{})
@@ -133,7 +153,7 @@ func TestBatcher_BatchSizeNotReached(t *testing.T) {
 	}))
 	defer testServer.Close()
 
-	batcher := NewBatcher(10, 3600, testServer.URL, logger)
+	batcher := NewBatcher(10, 3600, logger, WALConfig{Dir: t.TempDir()}, []sink.Sink{sink.NewHTTPSink(testServer.URL)}, testRetryPolicy, testBreakerConfig, DLQConfig{}, prometheus.NewRegistry())
 	go batcher.Run()
 
 	payload := model.Payload{
@@ -181,7 +201,7 @@ func TestBatcher_TimeBasedBatch(t *testing.T) {
 	}))
 	defer testServer.Close()
 
-	batcher := NewBatcher(10, 1, testServer.URL, logger)
+	batcher := NewBatcher(10, 1, logger, WALConfig{Dir: t.TempDir()}, []sink.Sink{sink.NewHTTPSink(testServer.URL)}, testRetryPolicy, testBreakerConfig, DLQConfig{}, prometheus.NewRegistry())
 	go batcher.Run()
 
 	payload := model.Payload{
@@ -199,3 +219,516 @@ func TestBatcher_TimeBasedBatch(t *testing.T) {
 		t.Errorf("Expected 1 payload sent due to time-based batch, but got %d", sentCount)
 	}
 }
+
+// Verifies that a full channel spills payloads to the WAL instead of dropping them.
+func TestBatcher_AddSpillsToWALWhenChannelFull(t *testing.T) {
+	logger := getTestLogger()
+	batcher := NewBatcher(1, 3600, logger, WALConfig{Dir: t.TempDir()}, []sink.Sink{sink.NewHTTPSink("http://localhost")}, testRetryPolicy, testBreakerConfig, DLQConfig{}, prometheus.NewRegistry())
+
+	payload := model.Payload{UserID: 1, Total: 1.0, Title: "WAL Spill"}
+
+	// size*2 buffered channel fills after two payloads with no consumer running.
+	batcher.Add(payload)
+	batcher.Add(payload)
+	batcher.Add(payload)
+
+	if got := batcher.PendingBytes(); got == 0 {
+		t.Errorf("Expected spilled payload to be reflected in PendingBytes, got %d", got)
+	}
+}
+
+// Verifies that payloads spilled to the WAL are replayed and ACK'd on Run.
+func TestBatcher_ReplaysWALOnRun(t *testing.T) {
+	logger := getTestLogger()
+	sentCount := 0
+
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payloads []model.Payload
+		if err := json.NewDecoder(r.Body).Decode(&payloads); err != nil {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		sentCount += len(payloads)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer testServer.Close()
+
+	walDir := t.TempDir()
+	batcher := NewBatcher(1, 3600, logger, WALConfig{Dir: walDir}, []sink.Sink{sink.NewHTTPSink(testServer.URL)}, testRetryPolicy, testBreakerConfig, DLQConfig{}, prometheus.NewRegistry())
+
+	payload := model.Payload{UserID: 1, Total: 1.0, Title: "WAL Replay"}
+	batcher.Add(payload)
+	batcher.Add(payload)
+	batcher.Add(payload)
+
+	if pending := batcher.PendingBytes(); pending == 0 {
+		t.Fatalf("Expected payload to be spilled to WAL before Run, got %d pending bytes", pending)
+	}
+
+	go batcher.Run()
+	time.Sleep(2 * time.Second)
+	batcher.Stop()
+
+	if sentCount == 0 {
+		t.Error("Expected WAL-spilled payload to be replayed and sent")
+	}
+	if pending := batcher.PendingBytes(); pending != 0 {
+		t.Errorf("Expected WAL segment to be ACK'd and truncated after replay, got %d pending bytes", pending)
+	}
+}
+
+// Verifies that a batch is fanned out to every configured sink independently.
+func TestBatcher_DispatchFansOutToAllSinks(t *testing.T) {
+	logger := getTestLogger()
+
+	var aCount, bCount int
+	serverA := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		aCount++
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer serverA.Close()
+	serverB := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		bCount++
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer serverB.Close()
+
+	batcher := NewBatcher(1, 1, logger, WALConfig{Dir: t.TempDir()}, []sink.Sink{
+		sink.NewHTTPSink(serverA.URL),
+		sink.NewHTTPSink(serverB.URL),
+	}, testRetryPolicy, testBreakerConfig, DLQConfig{}, prometheus.NewRegistry())
+	go batcher.Run()
+
+	batcher.Add(model.Payload{UserID: 1, Total: 1.0, Title: "Fan-out Test"})
+	time.Sleep(2 * time.Second)
+	batcher.Stop()
+
+	if aCount != 1 || bCount != 1 {
+		t.Errorf("Expected both sinks to receive the batch once, got serverA=%d serverB=%d", aCount, bCount)
+	}
+}
+
+// Verifies that a permanent (4xx) error is not retried.
+func TestBatcher_PermanentErrorIsNotRetried(t *testing.T) {
+	logger := getTestLogger()
+	attempts := 0
+
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer testServer.Close()
+
+	batcher := NewBatcher(1, 1, logger, WALConfig{Dir: t.TempDir()}, []sink.Sink{sink.NewHTTPSink(testServer.URL)}, testRetryPolicy, testBreakerConfig, DLQConfig{}, prometheus.NewRegistry())
+	go batcher.Run()
+
+	batcher.Add(model.Payload{UserID: 1, Total: 1.0, Title: "Permanent Failure"})
+	time.Sleep(500 * time.Millisecond)
+	batcher.Stop()
+
+	if attempts != 1 {
+		t.Errorf("Expected exactly 1 attempt for a permanent failure, got %d", attempts)
+	}
+}
+
+// Verifies that a permanently-rejected payload is routed to the dead-letter
+// queue with its status code and original payload recorded, and that
+// draining the queue empties it.
+func TestBatcher_PermanentErrorRoutesToDeadLetterQueue(t *testing.T) {
+	logger := getTestLogger()
+
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "bad payload", http.StatusBadRequest)
+	}))
+	defer testServer.Close()
+
+	dlqDir := t.TempDir()
+	batcher := NewBatcher(1, 1, logger, WALConfig{Dir: t.TempDir()}, []sink.Sink{sink.NewHTTPSink(testServer.URL)}, testRetryPolicy, testBreakerConfig, DLQConfig{Dir: dlqDir}, prometheus.NewRegistry())
+	go batcher.Run()
+
+	batcher.Add(model.Payload{UserID: 1, Total: 1.0, Title: "Dead Letter Me"})
+	time.Sleep(500 * time.Millisecond)
+	batcher.Stop()
+
+	envelopes, err := batcher.dlq.Drain()
+	if err != nil {
+		t.Fatalf("failed to drain dead-letter queue: %v", err)
+	}
+	if len(envelopes) != 1 {
+		t.Fatalf("expected 1 dead-lettered envelope, got %d", len(envelopes))
+	}
+	if envelopes[0].StatusCode != http.StatusBadRequest {
+		t.Errorf("expected status code %d, got %d", http.StatusBadRequest, envelopes[0].StatusCode)
+	}
+	if envelopes[0].Payload.Title != "Dead Letter Me" {
+		t.Errorf("expected original payload to be preserved, got %+v", envelopes[0].Payload)
+	}
+
+	// Draining already truncated the file; a second drain should be empty.
+	again, err := batcher.dlq.Drain()
+	if err != nil {
+		t.Fatalf("failed to drain dead-letter queue a second time: %v", err)
+	}
+	if len(again) != 0 {
+		t.Errorf("expected dead-letter queue to be empty after drain, got %d", len(again))
+	}
+}
+
+// Verifies that ReplayDLQ re-enqueues every dead-lettered payload into the
+// batcher's normal processing pipeline.
+func TestBatcher_ReplayDLQ(t *testing.T) {
+	logger := getTestLogger()
+	var mu sync.Mutex
+	received := 0
+
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		received++
+		mu.Unlock()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer testServer.Close()
+
+	dlqDir := t.TempDir()
+	batcher := NewBatcher(1, 1, logger, WALConfig{Dir: t.TempDir()}, []sink.Sink{sink.NewHTTPSink(testServer.URL)}, testRetryPolicy, testBreakerConfig, DLQConfig{Dir: dlqDir}, prometheus.NewRegistry())
+	go batcher.Run()
+	defer batcher.Stop()
+
+	if err := batcher.dlq.Add(DLQEnvelope{Payload: model.Payload{UserID: 1, Total: 1.0, Title: "Replay Me"}}); err != nil {
+		t.Fatalf("failed to seed dead-letter queue: %v", err)
+	}
+
+	n, err := batcher.ReplayDLQ()
+	if err != nil {
+		t.Fatalf("ReplayDLQ returned an error: %v", err)
+	}
+	if n != 1 {
+		t.Errorf("expected 1 payload replayed, got %d", n)
+	}
+
+	time.Sleep(500 * time.Millisecond)
+	mu.Lock()
+	defer mu.Unlock()
+	if received != 1 {
+		t.Errorf("expected replayed payload to be sent once, got %d", received)
+	}
+}
+
+// Verifies that the circuit breaker trips open after repeated failures and
+// short-circuits further sends to that sink.
+func TestBatcher_CircuitBreakerTripsOpen(t *testing.T) {
+	logger := getTestLogger()
+	attempts := 0
+
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		http.Error(w, "failed", http.StatusInternalServerError)
+	}))
+	defer testServer.Close()
+
+	breakerCfg := BreakerConfig{FailureThreshold: 1, Cooldown: time.Minute}
+	fastRetry := RetryPolicy{Base: 10 * time.Millisecond, Max: 10 * time.Millisecond, Multiplier: 1, MaxElapsed: time.Second, Jitter: false}
+
+	batcher := NewBatcher(1, 3600, logger, WALConfig{Dir: t.TempDir()}, []sink.Sink{sink.NewHTTPSink(testServer.URL)}, fastRetry, breakerCfg, DLQConfig{}, prometheus.NewRegistry())
+	go batcher.Run()
+
+	batcher.Add(model.Payload{UserID: 1, Total: 1.0, Title: "First Batch"})
+	time.Sleep(300 * time.Millisecond)
+
+	attemptsAfterFirstBatch := attempts
+
+	batcher.Add(model.Payload{UserID: 1, Total: 1.0, Title: "Second Batch"})
+	time.Sleep(300 * time.Millisecond)
+	batcher.Stop()
+
+	if attempts != attemptsAfterFirstBatch {
+		t.Errorf("Expected breaker to short-circuit the second batch without attempting a send, got %d additional attempts", attempts-attemptsAfterFirstBatch)
+	}
+	if pending := batcher.PendingBytes(); pending == 0 {
+		t.Error("Expected the short-circuited batch to be spilled to the WAL")
+	}
+}
+
+// Verifies that a WAL segment which partially failed replay (one sink ACK'd,
+// one didn't) isn't redelivered to the sink that already succeeded on a
+// later replay attempt.
+func TestBatcher_ReplayWALSkipsAlreadyAckedSinks(t *testing.T) {
+	logger := getTestLogger()
+	httpAttempts := 0
+	httpShouldFail := true
+
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		httpAttempts++
+		if httpShouldFail {
+			http.Error(w, "failed", http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer testServer.Close()
+
+	var stdoutBuf bytes.Buffer
+
+	walDir := t.TempDir()
+	// SegmentBytes: 1 rolls the segment closed immediately after the single
+	// spilled payload, so it's eligible for replay without waiting on a
+	// size-triggered rollover that would otherwise never happen.
+	batcher := NewBatcher(1, 3600, logger, WALConfig{Dir: walDir, SegmentBytes: 1}, []sink.Sink{
+		sink.NewHTTPSink(testServer.URL),
+		sink.NewStdoutSink(&stdoutBuf),
+	}, testRetryPolicy, testBreakerConfig, DLQConfig{}, prometheus.NewRegistry())
+
+	payload := model.Payload{UserID: 1, Total: 1.0, Title: "Partial Ack"}
+	batcher.Add(payload)
+	batcher.Add(payload)
+	batcher.Add(payload)
+
+	if pending := batcher.PendingBytes(); pending == 0 {
+		t.Fatalf("Expected payload to be spilled to WAL before replay, got %d pending bytes", pending)
+	}
+
+	batcher.replayWAL()
+	attemptsAfterFirstReplay := httpAttempts
+	if attemptsAfterFirstReplay == 0 {
+		t.Fatal("expected at least one http attempt on first replay")
+	}
+	if stdoutBuf.Len() == 0 {
+		t.Fatal("expected stdout sink to have ACK'd the segment on first replay")
+	}
+	if pending := batcher.PendingBytes(); pending == 0 {
+		t.Fatal("expected segment to remain pending after a partial replay failure")
+	}
+
+	// The breaker may still be cooling down from the failures above, so poll
+	// replayWAL rather than asserting the very next call redelivers.
+	stdoutLenAfterFirstReplay := stdoutBuf.Len()
+	httpShouldFail = false
+	deadline := time.Now().Add(3 * time.Second)
+	for batcher.PendingBytes() != 0 && time.Now().Before(deadline) {
+		batcher.replayWAL()
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	if pending := batcher.PendingBytes(); pending != 0 {
+		t.Errorf("expected segment to be fully ACK'd and truncated once the sink recovered, got %d pending bytes", pending)
+	}
+	if httpAttempts <= attemptsAfterFirstReplay {
+		t.Error("expected at least one additional http attempt once the sink recovered")
+	}
+	if stdoutBuf.Len() != stdoutLenAfterFirstReplay {
+		t.Error("expected stdout sink, already ACK'd, not to receive the segment again")
+	}
+}
+
+// Verifies that when sendBatch spills a partially-failed batch to the WAL,
+// it records the ACK for sinks that already succeeded live, so the very
+// first replay doesn't resend the segment to them too.
+func TestBatcher_SendBatchAcksAlreadySucceededSinksBeforeSpill(t *testing.T) {
+	logger := getTestLogger()
+
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "failed", http.StatusInternalServerError)
+	}))
+	defer testServer.Close()
+
+	var stdoutBuf bytes.Buffer
+
+	oneShotBreakerConfig := BreakerConfig{FailureThreshold: 1, Cooldown: time.Minute}
+	fastRetry := RetryPolicy{Base: 10 * time.Millisecond, Max: 10 * time.Millisecond, Multiplier: 1, MaxElapsed: time.Second, Jitter: false}
+
+	batcher := NewBatcher(1, 3600, logger, WALConfig{Dir: t.TempDir()}, []sink.Sink{
+		sink.NewHTTPSink(testServer.URL),
+		sink.NewStdoutSink(&stdoutBuf),
+	}, fastRetry, oneShotBreakerConfig, DLQConfig{}, prometheus.NewRegistry())
+	go batcher.Run()
+	defer batcher.Stop()
+
+	batcher.Add(model.Payload{UserID: 1, Total: 1.0, Title: "Partial Live Success"})
+
+	deadline := time.Now().Add(2 * time.Second)
+	for batcher.PendingBytes() == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if pending := batcher.PendingBytes(); pending == 0 {
+		t.Fatalf("expected batch to be spilled to WAL after the http sink failed, got %d pending bytes", pending)
+	}
+	stdoutLenAfterLiveSend := stdoutBuf.Len()
+	if stdoutLenAfterLiveSend == 0 {
+		t.Fatal("expected stdout sink to have succeeded on the live send")
+	}
+
+	batcher.replayWAL()
+
+	if stdoutBuf.Len() != stdoutLenAfterLiveSend {
+		t.Error("expected stdout sink, already delivered live, not to receive the segment again on replay")
+	}
+}
+
+// renamedSink overrides a sink's Name so two sinks of the same type get
+// independent per-sink breaker, result and ACK state.
+type renamedSink struct {
+	sink.Sink
+	name string
+}
+
+func (s renamedSink) Name() string { return s.name }
+
+// Verifies that two independently-dispatched failed batches never share a
+// still-open WAL segment, even with a SegmentBytes large enough that they
+// otherwise would: an earlier batch's per-sink ACK state must not be
+// contaminated by a later, unrelated batch's live-delivery outcome landing
+// on the same segment.
+func TestBatcher_SendBatchNeverSharesSegmentAcrossBatches(t *testing.T) {
+	logger := getTestLogger()
+
+	// sinkX never recovers, so every batch is spilled to the WAL and neither
+	// segment is ever fully ACK'd (keeping both around for inspection).
+	serverX := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "down", http.StatusInternalServerError)
+	}))
+	defer serverX.Close()
+
+	var mu sync.Mutex
+	sinkYFailing := true
+	var sinkYTitles []string
+	serverY := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payloads []model.Payload
+		if err := json.NewDecoder(r.Body).Decode(&payloads); err != nil {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		mu.Lock()
+		failing := sinkYFailing
+		if !failing {
+			for _, p := range payloads {
+				sinkYTitles = append(sinkYTitles, p.Title)
+			}
+		}
+		mu.Unlock()
+		if failing {
+			http.Error(w, "down", http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer serverY.Close()
+
+	fastRetry := RetryPolicy{Base: 10 * time.Millisecond, Max: 10 * time.Millisecond, Multiplier: 1, MaxElapsed: 200 * time.Millisecond, Jitter: false}
+	breakerCfg := BreakerConfig{FailureThreshold: 10, Cooldown: time.Millisecond}
+
+	// SegmentBytes this large would, without rotating the WAL around each
+	// spill, leave both batches' payloads sitting in the same still-open
+	// segment, since neither batch is anywhere near big enough to roll it.
+	batcher := NewBatcher(1, 3600, logger, WALConfig{Dir: t.TempDir(), SegmentBytes: 1 << 20}, []sink.Sink{
+		renamedSink{Sink: sink.NewHTTPSink(serverX.URL), name: "x"},
+		renamedSink{Sink: sink.NewHTTPSink(serverY.URL), name: "y"},
+	}, fastRetry, breakerCfg, DLQConfig{}, prometheus.NewRegistry())
+
+	batcher.sendBatch([]model.Payload{{UserID: 1, Total: 1.0, Title: "Batch A"}})
+	if pending := batcher.PendingBytes(); pending == 0 {
+		t.Fatal("expected first batch (failed on both sinks) to be spilled to WAL")
+	}
+
+	mu.Lock()
+	sinkYFailing = false
+	mu.Unlock()
+
+	batcher.sendBatch([]model.Payload{{UserID: 1, Total: 1.0, Title: "Batch B"}})
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		batcher.replayWAL()
+
+		mu.Lock()
+		gotA := false
+		for _, title := range sinkYTitles {
+			if title == "Batch A" {
+				gotA = true
+			}
+		}
+		mu.Unlock()
+		if gotA {
+			break
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	mu.Lock()
+	titles := append([]string(nil), sinkYTitles...)
+	mu.Unlock()
+
+	foundA, foundB := false, false
+	for _, title := range titles {
+		switch title {
+		case "Batch A":
+			foundA = true
+		case "Batch B":
+			foundB = true
+		}
+	}
+	if !foundA {
+		t.Error("expected batch A to eventually reach the sink on replay; its segment must have been wrongly ACK'd by batch B's live success")
+	}
+	if !foundB {
+		t.Error("expected batch B to have reached the sink on its live send")
+	}
+}
+
+// Verifies that a batch spilled to the WAL while a sink is down gets
+// redelivered by the periodic replay ticker once the sink recovers, without
+// needing to Stop and restart the batcher.
+func TestBatcher_PeriodicWALReplayRedeliversAfterRecovery(t *testing.T) {
+	logger := getTestLogger()
+
+	var mu sync.Mutex
+	failing := true
+
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if failing {
+			http.Error(w, "failed", http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer testServer.Close()
+
+	fastRetry := RetryPolicy{
+		Base:       10 * time.Millisecond,
+		Max:        20 * time.Millisecond,
+		Multiplier: 2,
+		MaxElapsed: 50 * time.Millisecond,
+		Jitter:     false,
+	}
+
+	batcher := NewBatcher(1, 3600, logger, WALConfig{
+		Dir:            t.TempDir(),
+		ReplayInterval: 100 * time.Millisecond,
+	}, []sink.Sink{sink.NewHTTPSink(testServer.URL)}, fastRetry, testBreakerConfig, DLQConfig{}, prometheus.NewRegistry())
+
+	go batcher.Run()
+	defer batcher.Stop()
+
+	batcher.Add(model.Payload{UserID: 1, Total: 1.0, Title: "Recovers Later"})
+
+	deadline := time.Now().Add(2 * time.Second)
+	for batcher.PendingBytes() == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if pending := batcher.PendingBytes(); pending == 0 {
+		t.Fatal("expected payload to be spilled to WAL while the sink was failing")
+	}
+
+	mu.Lock()
+	failing = false
+	mu.Unlock()
+
+	deadline = time.Now().Add(2 * time.Second)
+	for batcher.PendingBytes() != 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if pending := batcher.PendingBytes(); pending != 0 {
+		t.Errorf("expected periodic WAL replay to redeliver and clear the spilled batch, got %d pending bytes", pending)
+	}
+}
@@ -0,0 +1,107 @@
+package service
+
+import (
+	"sync"
+	"time"
+)
+
+// breakerState is one of the three states in a circuit breaker's state machine.
+type breakerState int
+
+const (
+	breakerClosed breakerState = iota
+	breakerOpen
+	breakerHalfOpen
+)
+
+// BreakerConfig configures the per-sink circuit breaker that protects
+// sendWithRetry from burning CPU on a sink that's consistently failing.
+type BreakerConfig struct {
+	FailureThreshold int
+	Cooldown         time.Duration
+}
+
+// DefaultBreakerConfig mirrors sensible defaults for a downstream HTTP sink.
+var DefaultBreakerConfig = BreakerConfig{
+	FailureThreshold: 5,
+	Cooldown:         30 * time.Second,
+}
+
+// circuitBreaker trips Open after FailureThreshold consecutive failures,
+// short-circuiting further attempts for Cooldown before allowing a single
+// HalfOpen probe to decide whether to close again or re-open.
+type circuitBreaker struct {
+	mu sync.Mutex
+
+	failureThreshold int
+	cooldown         time.Duration
+
+	state    breakerState
+	failures int
+	openedAt time.Time
+}
+
+func newCircuitBreaker(cfg BreakerConfig) *circuitBreaker {
+	return &circuitBreaker{failureThreshold: cfg.FailureThreshold, cooldown: cfg.Cooldown}
+}
+
+// Allow reports whether a call should be attempted, transitioning Open ->
+// HalfOpen (and admitting exactly one probe) once the cooldown has elapsed.
+func (b *circuitBreaker) Allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case breakerOpen:
+		if time.Since(b.openedAt) < b.cooldown {
+			return false
+		}
+		b.state = breakerHalfOpen
+		return true
+	case breakerHalfOpen:
+		// Only the call that flipped us into HalfOpen may proceed; everyone
+		// else is short-circuited until that probe resolves.
+		return false
+	default:
+		return true
+	}
+}
+
+// RecordSuccess closes the breaker and resets its failure count.
+func (b *circuitBreaker) RecordSuccess() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.state = breakerClosed
+	b.failures = 0
+}
+
+// RecordFailure increments the failure count, tripping Open once the
+// threshold is reached, or immediately re-opening after a failed probe.
+func (b *circuitBreaker) RecordFailure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == breakerHalfOpen {
+		b.open()
+		return
+	}
+
+	b.failures++
+	if b.failures >= b.failureThreshold {
+		b.open()
+	}
+}
+
+func (b *circuitBreaker) open() {
+	b.state = breakerOpen
+	b.openedAt = time.Now()
+	b.failures = 0
+}
+
+// IsOpen reports whether the breaker is currently tripped (Open or
+// HalfOpen, since only a single HalfOpen probe may pass through).
+func (b *circuitBreaker) IsOpen() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state != breakerClosed
+}
@@ -0,0 +1,170 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"webhook-receiver/internal/model"
+)
+
+// DLQEnvelope wraps a payload a sink permanently rejected, plus enough
+// detail about the rejection to diagnose or replay it later.
+type DLQEnvelope struct {
+	Payload      model.Payload `json:"payload"`
+	Sink         string        `json:"sink"`
+	StatusCode   int           `json:"status_code,omitempty"`
+	ResponseBody string        `json:"response_body,omitempty"`
+	Timestamp    time.Time     `json:"timestamp"`
+	Attempts     int           `json:"attempts"`
+}
+
+// DLQConfig controls where dead-lettered payloads are persisted and,
+// optionally, additionally forwarded to. A zero-value DLQConfig disables
+// dead-letter routing entirely.
+type DLQConfig struct {
+	// Dir holds a replayable JSONL file of dead-lettered envelopes; empty
+	// disables file-backed persistence (and therefore /dlq/replay).
+	Dir string
+	// Endpoint, if set, additionally POSTs each envelope to this HTTP
+	// endpoint for alerting.
+	Endpoint string
+}
+
+const dlqFileName = "dead-letters.jsonl"
+
+// deadLetterQueue persists permanently-rejected payloads to a JSONL file so
+// they survive a restart and can be replayed via /dlq/replay, optionally
+// also forwarding each envelope to an HTTP endpoint.
+type deadLetterQueue struct {
+	mu sync.Mutex
+
+	path     string
+	endpoint string
+	client   *http.Client
+}
+
+// newDeadLetterQueue builds a deadLetterQueue from cfg, returning nil, nil
+// if neither a directory nor an endpoint is configured.
+func newDeadLetterQueue(cfg DLQConfig) (*deadLetterQueue, error) {
+	if cfg.Dir == "" && cfg.Endpoint == "" {
+		return nil, nil
+	}
+
+	d := &deadLetterQueue{endpoint: cfg.Endpoint, client: http.DefaultClient}
+	if cfg.Dir != "" {
+		if err := os.MkdirAll(cfg.Dir, 0o755); err != nil {
+			return nil, fmt.Errorf("dlq: create dir: %w", err)
+		}
+		d.path = filepath.Join(cfg.Dir, dlqFileName)
+	}
+	return d, nil
+}
+
+// Add appends envelope to the dead-letter file (if configured) and
+// best-effort forwards it to the configured HTTP endpoint.
+func (d *deadLetterQueue) Add(envelope DLQEnvelope) error {
+	if d.path != "" {
+		line, err := json.Marshal(envelope)
+		if err != nil {
+			return fmt.Errorf("dlq: marshal envelope: %w", err)
+		}
+		line = append(line, '\n')
+
+		d.mu.Lock()
+		f, err := os.OpenFile(d.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			d.mu.Unlock()
+			return fmt.Errorf("dlq: open file: %w", err)
+		}
+		_, writeErr := f.Write(line)
+		f.Close()
+		d.mu.Unlock()
+		if writeErr != nil {
+			return fmt.Errorf("dlq: write envelope: %w", writeErr)
+		}
+	}
+
+	if d.endpoint != "" {
+		if err := d.forward(envelope); err != nil {
+			return fmt.Errorf("dlq: forward to endpoint: %w", err)
+		}
+	}
+	return nil
+}
+
+func (d *deadLetterQueue) forward(envelope DLQEnvelope) error {
+	data, err := json.Marshal(envelope)
+	if err != nil {
+		return fmt.Errorf("marshal envelope: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, d.endpoint, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("post: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Drain reads every dead-lettered envelope and truncates the backing file,
+// for use by /dlq/replay. Returns nil, nil when no file-backed DLQ is
+// configured.
+func (d *deadLetterQueue) Drain() ([]DLQEnvelope, error) {
+	if d.path == "" {
+		return nil, nil
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	f, err := os.Open(d.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("dlq: open file: %w", err)
+	}
+
+	var envelopes []DLQEnvelope
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 8*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		var e DLQEnvelope
+		if err := json.Unmarshal(line, &e); err != nil {
+			f.Close()
+			return nil, fmt.Errorf("dlq: decode envelope: %w", err)
+		}
+		envelopes = append(envelopes, e)
+	}
+	scanErr := scanner.Err()
+	f.Close()
+	if scanErr != nil {
+		return nil, fmt.Errorf("dlq: scan file: %w", scanErr)
+	}
+
+	if err := os.Truncate(d.path, 0); err != nil {
+		return nil, fmt.Errorf("dlq: truncate file: %w", err)
+	}
+	return envelopes, nil
+}
@@ -0,0 +1,77 @@
+package service
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// Metrics holds the Prometheus collectors a Batcher updates as it accepts
+// payloads and delivers batches. Each Batcher registers its own set against
+// whatever Registerer NewBatcher is given, so tests can use isolated
+// registries instead of the global default one.
+type Metrics struct {
+	BatchesSent          *prometheus.CounterVec
+	PayloadsReceived     prometheus.Counter
+	PayloadsDropped      *prometheus.CounterVec
+	BatchSize            prometheus.Histogram
+	BatchSendDuration    prometheus.Histogram
+	RetryAttempts        prometheus.Counter
+	WALPendingBytes      prometheus.Gauge
+	SinkUp               *prometheus.GaugeVec
+	PayloadsDeadLettered *prometheus.CounterVec
+}
+
+// NewMetrics builds a Metrics set and registers its collectors against reg.
+func NewMetrics(reg prometheus.Registerer) *Metrics {
+	m := &Metrics{
+		BatchesSent: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "batches_sent_total",
+			Help: "Total number of batches sent downstream, by outcome.",
+		}, []string{"status"}),
+		PayloadsReceived: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "payloads_received_total",
+			Help: "Total number of payloads accepted by the batcher.",
+		}),
+		PayloadsDropped: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "payloads_dropped_total",
+			Help: "Total number of payloads dropped without being queued, by reason.",
+		}, []string{"reason"}),
+		BatchSize: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:    "batch_size",
+			Help:    "Distribution of the number of payloads per sent batch.",
+			Buckets: prometheus.ExponentialBuckets(1, 2, 10),
+		}),
+		BatchSendDuration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:    "batch_send_duration_seconds",
+			Help:    "Time taken to fan a batch out to every configured sink.",
+			Buckets: prometheus.DefBuckets,
+		}),
+		RetryAttempts: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "retry_attempts_total",
+			Help: "Total number of sink send retries (not counting the first attempt).",
+		}),
+		WALPendingBytes: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "wal_pending_bytes",
+			Help: "Bytes currently held in unacknowledged WAL segments.",
+		}),
+		SinkUp: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "sink_up",
+			Help: "Whether a sink's circuit breaker is closed (1) or tripped (0).",
+		}, []string{"sink"}),
+		PayloadsDeadLettered: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "payloads_dead_lettered_total",
+			Help: "Total number of payloads routed to the dead-letter queue after a sink permanently rejected them, by sink.",
+		}, []string{"sink"}),
+	}
+
+	reg.MustRegister(
+		m.BatchesSent,
+		m.PayloadsReceived,
+		m.PayloadsDropped,
+		m.BatchSize,
+		m.BatchSendDuration,
+		m.RetryAttempts,
+		m.WALPendingBytes,
+		m.SinkUp,
+		m.PayloadsDeadLettered,
+	)
+
+	return m
+}
@@ -0,0 +1,39 @@
+package service
+
+import (
+	"math"
+	"math/rand"
+	"time"
+)
+
+// RetryPolicy configures the exponential backoff used between delivery
+// attempts to a single sink.
+type RetryPolicy struct {
+	Base       time.Duration
+	Max        time.Duration
+	Multiplier float64
+	MaxElapsed time.Duration
+	Jitter     bool
+}
+
+// DefaultRetryPolicy mirrors sensible defaults for a downstream HTTP sink.
+var DefaultRetryPolicy = RetryPolicy{
+	Base:       500 * time.Millisecond,
+	Max:        30 * time.Second,
+	Multiplier: 2.0,
+	MaxElapsed: 2 * time.Minute,
+	Jitter:     true,
+}
+
+// backoff returns the delay to wait after the given 1-indexed attempt fails
+// (so backoff(1) is Base), applying full-jitter randomization when enabled.
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	d := float64(p.Base) * math.Pow(p.Multiplier, float64(attempt-1))
+	if p.Max > 0 && d > float64(p.Max) {
+		d = float64(p.Max)
+	}
+	if p.Jitter {
+		d = rand.Float64() * d
+	}
+	return time.Duration(d)
+}
@@ -0,0 +1,37 @@
+package sink
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrPermanent marks a send failure the caller should not retry, e.g. a 4xx
+// response other than 408/429. Use errors.Is(err, ErrPermanent) to check.
+var ErrPermanent = errors.New("sink: permanent failure")
+
+// HTTPError carries the status code and a truncated response body from a
+// rejected HTTP-based sink send, so callers (e.g. dead-letter routing) can
+// recover that detail with errors.As instead of re-parsing the error text.
+type HTTPError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("unexpected status %d", e.StatusCode)
+}
+
+// permanentError wraps err to mark it non-retryable while still exposing the
+// underlying error via Unwrap.
+type permanentError struct{ err error }
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+func (e *permanentError) Is(target error) bool {
+	return target == ErrPermanent
+}
+
+// Permanent wraps err so callers can distinguish it from a transient failure.
+func Permanent(err error) error {
+	return &permanentError{err: err}
+}
@@ -0,0 +1,61 @@
+package sink
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"webhook-receiver/internal/model"
+
+	kafka "github.com/segmentio/kafka-go"
+)
+
+// KafkaSink publishes each payload in a batch as an individual message to a
+// Kafka topic.
+type KafkaSink struct {
+	writer *kafka.Writer
+}
+
+// NewKafkaSink builds a Sink that publishes to topic on the given brokers.
+func NewKafkaSink(brokers []string, topic string) *KafkaSink {
+	return &KafkaSink{
+		writer: &kafka.Writer{
+			Addr:     kafka.TCP(brokers...),
+			Topic:    topic,
+			Balancer: &kafka.LeastBytes{},
+		},
+	}
+}
+
+func newKafkaSinkFromEnv(getenv GetEnv) (Sink, error) {
+	brokersCSV := getenv("SINK_KAFKA_BROKERS", "")
+	if brokersCSV == "" {
+		return nil, fmt.Errorf("SINK_KAFKA_BROKERS is required")
+	}
+	topic := getenv("SINK_KAFKA_TOPIC", "webhook-payloads")
+	return NewKafkaSink(strings.Split(brokersCSV, ","), topic), nil
+}
+
+// Name implements Sink.
+func (s *KafkaSink) Name() string { return "kafka" }
+
+// Send implements Sink.
+func (s *KafkaSink) Send(ctx context.Context, batch []model.Payload) error {
+	messages := make([]kafka.Message, 0, len(batch))
+	for _, p := range batch {
+		data, err := json.Marshal(p)
+		if err != nil {
+			return fmt.Errorf("kafka sink: marshal payload: %w", err)
+		}
+		messages = append(messages, kafka.Message{Value: data})
+	}
+
+	if err := s.writer.WriteMessages(ctx, messages...); err != nil {
+		return fmt.Errorf("kafka sink: write messages: %w", err)
+	}
+	return nil
+}
+
+// Close implements Sink.
+func (s *KafkaSink) Close() error { return s.writer.Close() }
@@ -0,0 +1,36 @@
+package sink
+
+import "fmt"
+
+// GetEnv looks up key, falling back to defaultVal. It matches the signature
+// of config.GetEnv so factories can be wired directly to it without an
+// import cycle back into the config package.
+type GetEnv func(key, defaultVal string) string
+
+// Factory builds a Sink from environment configuration.
+type Factory func(getenv GetEnv) (Sink, error)
+
+var factories = map[string]Factory{
+	"http":   newHTTPSinkFromEnv,
+	"kafka":  newKafkaSinkFromEnv,
+	"stdout": newStdoutSinkFromEnv,
+	"s3":     newS3SinkFromEnv,
+}
+
+// BuildFromNames constructs one Sink per enabled name, in the order given,
+// using getenv to resolve each sink's SINK_<NAME>_* configuration.
+func BuildFromNames(names []string, getenv GetEnv) ([]Sink, error) {
+	sinks := make([]Sink, 0, len(names))
+	for _, name := range names {
+		factory, ok := factories[name]
+		if !ok {
+			return nil, fmt.Errorf("sink: unknown sink %q", name)
+		}
+		s, err := factory(getenv)
+		if err != nil {
+			return nil, fmt.Errorf("sink: build %q: %w", name, err)
+		}
+		sinks = append(sinks, s)
+	}
+	return sinks, nil
+}
@@ -0,0 +1,68 @@
+package sink
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"webhook-receiver/internal/model"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// S3Sink rolls each batch up into a single timestamped JSON object in an
+// S3-compatible object store, for durable long-term archival.
+type S3Sink struct {
+	client *s3.Client
+	bucket string
+	prefix string
+}
+
+// NewS3Sink builds a Sink that writes rollup objects to bucket/prefix.
+func NewS3Sink(client *s3.Client, bucket, prefix string) *S3Sink {
+	return &S3Sink{client: client, bucket: bucket, prefix: prefix}
+}
+
+func newS3SinkFromEnv(getenv GetEnv) (Sink, error) {
+	bucket := getenv("SINK_S3_BUCKET", "")
+	if bucket == "" {
+		return nil, fmt.Errorf("SINK_S3_BUCKET is required")
+	}
+	prefix := getenv("SINK_S3_PREFIX", "webhook-receiver")
+
+	cfg, err := awsconfig.LoadDefaultConfig(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("load AWS config: %w", err)
+	}
+	return NewS3Sink(s3.NewFromConfig(cfg), bucket, prefix), nil
+}
+
+// Name implements Sink.
+func (s *S3Sink) Name() string { return "s3" }
+
+// Send implements Sink, writing the whole batch as one rollup object keyed
+// by the send time so repeated calls never collide.
+func (s *S3Sink) Send(ctx context.Context, batch []model.Payload) error {
+	data, err := json.Marshal(batch)
+	if err != nil {
+		return fmt.Errorf("s3 sink: marshal batch: %w", err)
+	}
+
+	key := fmt.Sprintf("%s/%s.json", s.prefix, time.Now().UTC().Format("20060102T150405.000000000"))
+	_, err = s.client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+		Body:   bytes.NewReader(data),
+	})
+	if err != nil {
+		return fmt.Errorf("s3 sink: put object: %w", err)
+	}
+	return nil
+}
+
+// Close implements Sink. The S3 client holds no long-lived connections to close.
+func (s *S3Sink) Close() error { return nil }
@@ -0,0 +1,22 @@
+// Package sink provides the pluggable downstream destinations a Batcher can
+// fan a batch out to (HTTP, Kafka, stdout/file, S3), plus a registry that
+// builds them from environment configuration.
+package sink
+
+import (
+	"context"
+
+	"webhook-receiver/internal/model"
+)
+
+// Sink delivers a batch of payloads to a single downstream destination.
+// Implementations must be safe for concurrent use by a single Batcher.
+type Sink interface {
+	// Name identifies the sink for logging, metrics, and per-sink retry state.
+	Name() string
+	// Send delivers batch to the destination, returning a non-nil error if
+	// the destination did not accept it.
+	Send(ctx context.Context, batch []model.Payload) error
+	// Close releases any resources (connections, file handles) held by the sink.
+	Close() error
+}
@@ -0,0 +1,58 @@
+package sink
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"webhook-receiver/internal/model"
+)
+
+// StdoutSink writes each payload in a batch as a newline-delimited JSON
+// record to an io.Writer, defaulting to os.Stdout or a configured file.
+type StdoutSink struct {
+	out    io.Writer
+	closer io.Closer
+}
+
+// NewStdoutSink builds a Sink that writes newline-delimited JSON to w.
+func NewStdoutSink(w io.Writer) *StdoutSink {
+	return &StdoutSink{out: w}
+}
+
+func newStdoutSinkFromEnv(getenv GetEnv) (Sink, error) {
+	path := getenv("SINK_STDOUT_PATH", "")
+	if path == "" {
+		return NewStdoutSink(os.Stdout), nil
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("open %s: %w", path, err)
+	}
+	return &StdoutSink{out: f, closer: f}, nil
+}
+
+// Name implements Sink.
+func (s *StdoutSink) Name() string { return "stdout" }
+
+// Send implements Sink.
+func (s *StdoutSink) Send(ctx context.Context, batch []model.Payload) error {
+	enc := json.NewEncoder(s.out)
+	for _, p := range batch {
+		if err := enc.Encode(p); err != nil {
+			return fmt.Errorf("stdout sink: encode payload: %w", err)
+		}
+	}
+	return nil
+}
+
+// Close implements Sink, closing the backing file if SINK_STDOUT_PATH was set.
+func (s *StdoutSink) Close() error {
+	if s.closer == nil {
+		return nil
+	}
+	return s.closer.Close()
+}
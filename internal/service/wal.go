@@ -0,0 +1,376 @@
+package service
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"webhook-receiver/internal/model"
+)
+
+// WALConfig controls the on-disk spill behavior of a Batcher. A zero-value
+// Dir disables the WAL entirely, preserving the old drop-on-full behavior.
+type WALConfig struct {
+	Dir          string
+	MaxBytes     int64
+	SegmentBytes int64
+
+	// ReplayInterval controls how often the Batcher re-scans the WAL for
+	// segments to redeliver while running, not just once at startup. A
+	// zero value falls back to defaultWALReplayInterval.
+	ReplayInterval time.Duration
+}
+
+// defaultWALReplayInterval is used when WALConfig.ReplayInterval is unset.
+const defaultWALReplayInterval = 30 * time.Second
+
+const walSegmentPrefix = "segment-"
+
+// ackedSinksSuffix names the sidecar file that tracks, per segment, which
+// sinks have already ACK'd it.
+const ackedSinksSuffix = ".acked"
+
+// wal is a bounded, segmented append-only queue backing a Batcher. Payloads
+// are appended as newline-delimited JSON records; a segment is only removed
+// once every payload it holds has been acknowledged by the downstream sink.
+type wal struct {
+	mu           sync.Mutex
+	dir          string
+	maxBytes     int64
+	segmentBytes int64
+
+	cur      *os.File
+	curBytes int64
+	curSeq   int64
+
+	pendingBytes int64
+}
+
+// newWAL opens (creating if necessary) the WAL directory and resumes
+// sequencing after the highest existing segment number.
+func newWAL(cfg WALConfig) (*wal, error) {
+	if cfg.Dir == "" {
+		return nil, nil
+	}
+	if err := os.MkdirAll(cfg.Dir, 0o755); err != nil {
+		return nil, fmt.Errorf("wal: create dir: %w", err)
+	}
+
+	w := &wal{
+		dir:          cfg.Dir,
+		maxBytes:     cfg.MaxBytes,
+		segmentBytes: cfg.SegmentBytes,
+	}
+
+	segments, err := w.segmentPaths()
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range segments {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, fmt.Errorf("wal: stat %s: %w", path, err)
+		}
+		w.pendingBytes += info.Size()
+		if seq := segmentSeq(path); seq > w.curSeq {
+			w.curSeq = seq
+		}
+	}
+
+	return w, nil
+}
+
+// segmentPaths returns existing segment files sorted oldest-first.
+func (w *wal) segmentPaths() ([]string, error) {
+	entries, err := os.ReadDir(w.dir)
+	if err != nil {
+		return nil, fmt.Errorf("wal: read dir: %w", err)
+	}
+	var paths []string
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasPrefix(e.Name(), walSegmentPrefix) || strings.HasSuffix(e.Name(), ackedSinksSuffix) {
+			continue
+		}
+		paths = append(paths, filepath.Join(w.dir, e.Name()))
+	}
+	sort.Slice(paths, func(i, j int) bool { return segmentSeq(paths[i]) < segmentSeq(paths[j]) })
+	return paths, nil
+}
+
+func segmentSeq(path string) int64 {
+	name := filepath.Base(path)
+	seq, _ := strconv.ParseInt(strings.TrimPrefix(name, walSegmentPrefix), 10, 64)
+	return seq
+}
+
+// Append serializes payload as a single JSON line onto the current segment,
+// rolling (and fsync'ing) to a new segment once it exceeds segmentBytes, and
+// returns the path of the segment the payload was written to so a caller
+// appending a whole batch can tell whether it landed on one segment or was
+// split across a roll.
+func (w *wal) Append(payload model.Payload) (string, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.maxBytes > 0 && w.pendingBytes >= w.maxBytes {
+		return "", fmt.Errorf("wal: disk spill full (%d bytes pending)", w.pendingBytes)
+	}
+
+	if w.cur == nil {
+		if err := w.rollSegmentLocked(); err != nil {
+			return "", err
+		}
+	}
+
+	line, err := json.Marshal(payload)
+	if err != nil {
+		return "", fmt.Errorf("wal: marshal payload: %w", err)
+	}
+	line = append(line, '\n')
+
+	n, err := w.cur.Write(line)
+	if err != nil {
+		return "", fmt.Errorf("wal: write record: %w", err)
+	}
+	w.curBytes += int64(n)
+	w.pendingBytes += int64(n)
+	path := w.cur.Name()
+
+	if w.segmentBytes > 0 && w.curBytes >= w.segmentBytes {
+		if err := w.rollSegmentLocked(); err != nil {
+			return "", err
+		}
+	}
+
+	return path, nil
+}
+
+// rollSegmentLocked fsyncs and closes the current segment (if any) and
+// opens a fresh one. Callers must hold w.mu.
+func (w *wal) rollSegmentLocked() error {
+	if w.cur != nil {
+		if err := w.cur.Sync(); err != nil {
+			return fmt.Errorf("wal: fsync segment: %w", err)
+		}
+		if err := w.cur.Close(); err != nil {
+			return fmt.Errorf("wal: close segment: %w", err)
+		}
+	}
+
+	w.curSeq++
+	path := filepath.Join(w.dir, fmt.Sprintf("%s%020d", walSegmentPrefix, w.curSeq))
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("wal: open segment: %w", err)
+	}
+	w.cur = f
+	w.curBytes = 0
+	return nil
+}
+
+// walSegment is a replayed, not-yet-fully-acknowledged segment of payloads.
+// ackedSinks records which sinks have already taken delivery of it across
+// prior replay attempts, so a segment that partially succeeds isn't
+// redelivered to sinks that already have it.
+type walSegment struct {
+	path       string
+	payloads   []model.Payload
+	ackedSinks map[string]bool
+}
+
+// ackedSinksPath returns the sidecar file a segment's per-sink ACKs are
+// recorded in.
+func ackedSinksPath(segmentPath string) string {
+	return segmentPath + ackedSinksSuffix
+}
+
+// readAckedSinks reads the set of sink names already recorded as having
+// ACK'd path's segment. A missing sidecar means no sink has ACK'd it yet.
+func readAckedSinks(path string) (map[string]bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return map[string]bool{}, nil
+		}
+		return nil, fmt.Errorf("wal: open acked-sinks sidecar: %w", err)
+	}
+	defer f.Close()
+
+	acked := map[string]bool{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if name := strings.TrimSpace(scanner.Text()); name != "" {
+			acked[name] = true
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("wal: scan acked-sinks sidecar: %w", err)
+	}
+	return acked, nil
+}
+
+// PendingSegments reads every segment on disk except the one currently
+// being appended to, in oldest-first order, for replay (at startup and on
+// every periodic replay tick).
+func (w *wal) PendingSegments() ([]walSegment, error) {
+	w.mu.Lock()
+	var curPath string
+	if w.cur != nil {
+		curPath = w.cur.Name()
+	}
+	w.mu.Unlock()
+
+	paths, err := w.segmentPaths()
+	if err != nil {
+		return nil, err
+	}
+
+	var segments []walSegment
+	for _, path := range paths {
+		if path == curPath {
+			continue
+		}
+		payloads, err := readSegment(path)
+		if err != nil {
+			return nil, err
+		}
+		if len(payloads) == 0 {
+			continue
+		}
+		acked, err := readAckedSinks(ackedSinksPath(path))
+		if err != nil {
+			return nil, err
+		}
+		segments = append(segments, walSegment{path: path, payloads: payloads, ackedSinks: acked})
+	}
+	return segments, nil
+}
+
+func readSegment(path string) ([]model.Payload, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("wal: open segment: %w", err)
+	}
+	defer f.Close()
+
+	var payloads []model.Payload
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 8*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		var p model.Payload
+		if err := json.Unmarshal(line, &p); err != nil {
+			return nil, fmt.Errorf("wal: decode record: %w", err)
+		}
+		payloads = append(payloads, p)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("wal: scan segment: %w", err)
+	}
+	return payloads, nil
+}
+
+// AckSink records that sinkName has taken delivery of segment, so a later
+// replay attempt (after some other sink in the same segment fails) only
+// resends to sinks that haven't ACK'd it yet.
+func (w *wal) AckSink(segment walSegment, sinkName string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	f, err := os.OpenFile(ackedSinksPath(segment.path), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("wal: open acked-sinks sidecar: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := f.WriteString(sinkName + "\n"); err != nil {
+		return fmt.Errorf("wal: write acked sink: %w", err)
+	}
+	return f.Sync()
+}
+
+// Ack truncates a fully-delivered segment (and its acked-sinks sidecar, if
+// any) from disk, freeing its bytes from the pending total.
+func (w *wal) Ack(segment walSegment) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	info, err := os.Stat(segment.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("wal: stat segment: %w", err)
+	}
+
+	if err := os.Remove(segment.path); err != nil {
+		return fmt.Errorf("wal: remove segment: %w", err)
+	}
+	if err := os.Remove(ackedSinksPath(segment.path)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("wal: remove acked-sinks sidecar: %w", err)
+	}
+	w.pendingBytes -= info.Size()
+	if w.pendingBytes < 0 {
+		w.pendingBytes = 0
+	}
+	return nil
+}
+
+// Rotate closes the current segment (if it holds any data) and opens a
+// fresh one, so whatever has been appended so far becomes a closed segment
+// that PendingSegments can see instead of skipping as "still being written
+// to". Replay calls this first so a segment that never grew large enough
+// to roll on its own (the common case for a lightly-loaded sink outage)
+// still becomes visible to replay instead of sitting in the open segment
+// indefinitely.
+func (w *wal) Rotate() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.cur == nil || w.curBytes == 0 {
+		return nil
+	}
+	return w.rollSegmentLocked()
+}
+
+// PendingBytes reports the total size of unacknowledged WAL segments,
+// including the one currently being written to.
+func (w *wal) PendingBytes() int64 {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.pendingBytes
+}
+
+// CheckWritable probes the WAL directory with a throwaway temp file, for use
+// by readiness checks.
+func (w *wal) CheckWritable() error {
+	f, err := os.CreateTemp(w.dir, ".health-*")
+	if err != nil {
+		return fmt.Errorf("wal: dir not writable: %w", err)
+	}
+	name := f.Name()
+	f.Close()
+	return os.Remove(name)
+}
+
+// Close fsyncs and closes the active segment.
+func (w *wal) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.cur == nil {
+		return nil
+	}
+	if err := w.cur.Sync(); err != nil {
+		return err
+	}
+	return w.cur.Close()
+}